@@ -0,0 +1,333 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder generates dashboard models from Go code.
+//
+//	d, err := builder.NewDashboard("PostgreSQL").
+//		WithUID("postgres").
+//		WithDatasource(&sdk.DataSourceRef{UID: "${ds}"}).
+//		AddVariable(builder.DatasourceVariable("ds", "prometheus")).
+//		AddRow("Connections").
+//		AddTimeseries("Active connections", builder.Unit("short")).
+//		AddQuery(builder.PrometheusQuery(`sum(pg_stat_activity_count{state="active"})`, "active")).
+//		Build()
+//
+// Panels get sequential ids and are placed on the grid in the order they are added, see package layout.
+package builder
+
+import (
+	"errors"
+	"fmt"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/layout"
+	"go.openviz.dev/grafana-sdk/migrate"
+)
+
+// PanelOption configures a panel added to the dashboard.
+type PanelOption func(p *sdk.Panel)
+
+// Width sets the number of grid columns the panel spans, at most 24. Panels are layout.DefaultWidth
+// columns wide by default.
+func Width(w int) PanelOption {
+	return func(p *sdk.Panel) {
+		p.GridPos.W = w
+	}
+}
+
+// Height sets the height of the panel in grid units of 30 pixels, layout.DefaultHeight by default.
+func Height(h int) PanelOption {
+	return func(p *sdk.Panel) {
+		p.GridPos.H = h
+	}
+}
+
+// Description sets the description shown in the panel header.
+func Description(s string) PanelOption {
+	return func(p *sdk.Panel) {
+		p.Description = s
+	}
+}
+
+// Unit sets the unit of the panel values, e.g. bytes or percentunit.
+func Unit(unit string) PanelOption {
+	return func(p *sdk.Panel) {
+		if p.FieldConfig == nil {
+			p.FieldConfig = &sdk.FieldConfigSource{}
+		}
+		p.FieldConfig.Defaults.Unit = unit
+	}
+}
+
+// Datasource sets the datasource of the panel, overriding the one set with WithDatasource.
+func Datasource(ref *sdk.DataSourceRef) PanelOption {
+	return func(p *sdk.Panel) {
+		p.Datasource = ref
+	}
+}
+
+// Options sets the options of the panel plugin.
+func Options(options map[string]any) PanelOption {
+	return func(p *sdk.Panel) {
+		p.Options = options
+	}
+}
+
+// Repeat repeats the panel for every value of the variable.
+func Repeat(variable string) PanelOption {
+	return func(p *sdk.Panel) {
+		p.Repeat = variable
+	}
+}
+
+// DashboardBuilder builds a dashboard model. Its methods return the builder so calls can be chained;
+// the first error, e.g. a query added before any panel, is returned by Build.
+type DashboardBuilder struct {
+	dashboard  *sdk.Dashboard
+	datasource *sdk.DataSourceRef
+	err        error
+
+	nextID int
+	// row is the index of the current row in dashboard.Panels, or -1 before the first row.
+	row int
+	// panel is the last added panel that is not a row.
+	panel *sdk.Panel
+	// grid places the top level panels and rowGrid the panels nested in the current collapsed row.
+	grid, rowGrid layout.Grid
+}
+
+// NewDashboard starts a dashboard with the given title.
+func NewDashboard(title string) *DashboardBuilder {
+	return &DashboardBuilder{
+		dashboard: &sdk.Dashboard{
+			Title:         title,
+			Editable:      true,
+			SchemaVersion: migrate.LatestSchemaVersion,
+			Time:          &sdk.TimeRange{From: "now-6h", To: "now"},
+		},
+		nextID: 1,
+		row:    -1,
+	}
+}
+
+func (b *DashboardBuilder) fail(err error) *DashboardBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// WithUID sets the uid of the dashboard.
+func (b *DashboardBuilder) WithUID(uid string) *DashboardBuilder {
+	b.dashboard.UID = uid
+	return b
+}
+
+// WithDescription sets the description of the dashboard.
+func (b *DashboardBuilder) WithDescription(s string) *DashboardBuilder {
+	b.dashboard.Description = s
+	return b
+}
+
+// WithTags adds tags to the dashboard.
+func (b *DashboardBuilder) WithTags(tags ...string) *DashboardBuilder {
+	b.dashboard.Tags = append(b.dashboard.Tags, tags...)
+	return b
+}
+
+// WithTime sets the default time range, e.g. now-1h to now.
+func (b *DashboardBuilder) WithTime(from, to string) *DashboardBuilder {
+	b.dashboard.Time = &sdk.TimeRange{From: from, To: to}
+	return b
+}
+
+// WithRefresh sets the auto refresh interval, e.g. 30s.
+func (b *DashboardBuilder) WithRefresh(interval string) *DashboardBuilder {
+	b.dashboard.Refresh = interval
+	return b
+}
+
+// WithDatasource sets the datasource of the panels added afterwards without the Datasource option.
+// It is typically a datasource variable such as ${ds}.
+func (b *DashboardBuilder) WithDatasource(ref *sdk.DataSourceRef) *DashboardBuilder {
+	b.datasource = ref
+	return b
+}
+
+// AddVariable adds a template variable.
+func (b *DashboardBuilder) AddVariable(v sdk.Variable) *DashboardBuilder {
+	if v.Name == "" {
+		return b.fail(errors.New("variable has no name"))
+	}
+	if b.dashboard.Templating == nil {
+		b.dashboard.Templating = &sdk.Templating{}
+	}
+	for _, existing := range b.dashboard.Templating.List {
+		if existing.Name == v.Name {
+			return b.fail(fmt.Errorf("variable %q is already defined", v.Name))
+		}
+	}
+	b.dashboard.Templating.List = append(b.dashboard.Templating.List, v)
+	return b
+}
+
+// AddRow starts a row. The panels added afterwards belong to it until the next row is added.
+func (b *DashboardBuilder) AddRow(title string) *DashboardBuilder {
+	return b.addRow(title, false)
+}
+
+// AddCollapsedRow starts a collapsed row. The panels added afterwards are nested in it and only
+// shown once the row is expanded.
+func (b *DashboardBuilder) AddCollapsedRow(title string) *DashboardBuilder {
+	return b.addRow(title, true)
+}
+
+func (b *DashboardBuilder) addRow(title string, collapsed bool) *DashboardBuilder {
+	b.dashboard.Panels = append(b.dashboard.Panels, sdk.Panel{
+		ID:        b.id(),
+		Type:      "row",
+		Title:     title,
+		Collapsed: collapsed,
+		GridPos:   b.grid.Row(),
+	})
+	b.row = len(b.dashboard.Panels) - 1
+	b.panel = nil
+	// The panels of a collapsed row are laid out as if it was expanded, but take no space until it is.
+	b.rowGrid = b.grid
+	return b
+}
+
+func (b *DashboardBuilder) inCollapsedRow() bool {
+	return b.row >= 0 && b.dashboard.Panels[b.row].Collapsed
+}
+
+// AddPanel adds a panel of the given plugin type.
+func (b *DashboardBuilder) AddPanel(typ, title string, opts ...PanelOption) *DashboardBuilder {
+	p := sdk.Panel{
+		ID:         b.id(),
+		Type:       typ,
+		Title:      title,
+		Datasource: b.datasource,
+	}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	if p.GridPos.W < 0 || p.GridPos.W > layout.Columns {
+		return b.fail(fmt.Errorf("panel %q must be 1 to %d columns wide, got %d", title, layout.Columns, p.GridPos.W))
+	}
+	if p.GridPos.H < 0 {
+		return b.fail(fmt.Errorf("panel %q must not have a negative height, got %d", title, p.GridPos.H))
+	}
+
+	if b.inCollapsedRow() {
+		p.GridPos = b.rowGrid.Place(p.GridPos.W, p.GridPos.H)
+		row := &b.dashboard.Panels[b.row]
+		row.Panels = append(row.Panels, p)
+		b.panel = &row.Panels[len(row.Panels)-1]
+	} else {
+		p.GridPos = b.grid.Place(p.GridPos.W, p.GridPos.H)
+		b.dashboard.Panels = append(b.dashboard.Panels, p)
+		b.panel = &b.dashboard.Panels[len(b.dashboard.Panels)-1]
+	}
+	return b
+}
+
+// AddTimeseries adds a time series panel.
+func (b *DashboardBuilder) AddTimeseries(title string, opts ...PanelOption) *DashboardBuilder {
+	return b.AddPanel("timeseries", title, opts...)
+}
+
+// AddStat adds a stat panel.
+func (b *DashboardBuilder) AddStat(title string, opts ...PanelOption) *DashboardBuilder {
+	return b.AddPanel("stat", title, opts...)
+}
+
+// AddGauge adds a gauge panel.
+func (b *DashboardBuilder) AddGauge(title string, opts ...PanelOption) *DashboardBuilder {
+	return b.AddPanel("gauge", title, opts...)
+}
+
+// AddTable adds a table panel.
+func (b *DashboardBuilder) AddTable(title string, opts ...PanelOption) *DashboardBuilder {
+	return b.AddPanel("table", title, opts...)
+}
+
+// AddText adds a text panel showing the given markdown.
+func (b *DashboardBuilder) AddText(title, markdown string, opts ...PanelOption) *DashboardBuilder {
+	opts = append([]PanelOption{Options(map[string]any{"mode": "markdown", "content": markdown})}, opts...)
+	return b.AddPanel("text", title, opts...)
+}
+
+// AddQuery adds a query to the last added panel. Queries without refId are named A, B, C and so on.
+func (b *DashboardBuilder) AddQuery(t sdk.Target) *DashboardBuilder {
+	if b.panel == nil {
+		return b.fail(errors.New("query added before any panel"))
+	}
+	if t.RefID == "" {
+		t.RefID = refID(len(b.panel.Targets))
+	}
+	for _, existing := range b.panel.Targets {
+		if existing.RefID == t.RefID {
+			return b.fail(fmt.Errorf("panel %q already has a query %s", b.panel.Title, t.RefID))
+		}
+	}
+	b.panel.Targets = append(b.panel.Targets, t)
+	return b
+}
+
+func (b *DashboardBuilder) id() int {
+	id := b.nextID
+	b.nextID++
+	return id
+}
+
+// refID returns the i-th query name: A to Z, then AA, AB and so on.
+func refID(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return refID(i/26-1) + refID(i%26)
+}
+
+// Build returns the dashboard model, or the first error of the chain. The builder must not be used
+// afterwards.
+func (b *DashboardBuilder) Build() (*sdk.Dashboard, error) {
+	if b.err != nil {
+		return nil, fmt.Errorf("failed to build dashboard %q, reason: %w", b.dashboard.Title, b.err)
+	}
+	if b.dashboard.Title == "" {
+		return nil, errors.New("failed to build dashboard, reason: missing title")
+	}
+	return b.dashboard, nil
+}
+
+// GrafanaDashboard builds the dashboard and wraps it for Client.SetDashboard, replacing any
+// dashboard with the same uid in the folder with the given uid.
+func (b *DashboardBuilder) GrafanaDashboard(folderUID string) (*sdk.GrafanaDashboard, error) {
+	d, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := d.RawExtension()
+	if err != nil {
+		return nil, err
+	}
+	return &sdk.GrafanaDashboard{
+		Dashboard: raw,
+		FolderUid: folderUID,
+		Overwrite: true,
+	}, nil
+}
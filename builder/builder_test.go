@@ -0,0 +1,171 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/internal/testutil"
+	"go.openviz.dev/grafana-sdk/layout"
+	"go.openviz.dev/grafana-sdk/lint"
+)
+
+func TestDashboardBuilder_Build(t *testing.T) {
+	ds := &sdk.DataSourceRef{UID: "${ds}"}
+	d, err := NewDashboard("PostgreSQL").
+		WithUID("postgres-db1").
+		WithTags("postgres").
+		WithRefresh("30s").
+		WithDatasource(ds).
+		AddVariable(DatasourceVariable("ds", "prometheus")).
+		AddVariable(MultiValue(QueryVariable("db", ds, "label_values(pg_database_size_bytes, datname)"))).
+		AddRow("Overview").
+		AddStat("Up", Width(6), Height(4)).
+		AddQuery(PrometheusQuery("pg_up", "")).
+		AddTimeseries("Connections", Width(18), Unit("short")).
+		AddQuery(PrometheusQuery(`sum(pg_stat_activity_count{datname=~"$db"}) by (state)`, "{{state}}")).
+		AddQuery(sdk.Target{RefID: "max", Expr: "pg_settings_max_connections"}).
+		AddQuery(PrometheusQuery("pg_stat_activity_max_tx_duration", "")).
+		AddTable("Sizes", Width(24)).
+		AddCollapsedRow("Details").
+		AddText("Notes", "# Runbook").
+		AddTimeseries("Locks", Datasource(&sdk.DataSourceRef{UID: "${ds}"}), Repeat("db")).
+		AddRow("Logs").
+		AddPanel("logs", "Errors", Width(24)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := `{
+  "uid": "postgres-db1", "title": "PostgreSQL", "tags": ["postgres"], "editable": true,
+  "time": {"from": "now-6h", "to": "now"}, "refresh": "30s", "schemaVersion": 39,
+  "templating": {"list": [
+    {"name": "ds", "type": "datasource", "query": "prometheus"},
+    {"name": "db", "type": "query", "datasource": {"uid": "${ds}"}, "query": "label_values(pg_database_size_bytes, datname)",
+     "definition": "label_values(pg_database_size_bytes, datname)", "refresh": 1, "multi": true, "includeAll": true}
+  ]},
+  "panels": [
+    {"id": 1, "type": "row", "title": "Overview", "gridPos": {"x": 0, "y": 0, "w": 24, "h": 1}},
+    {"id": 2, "type": "stat", "title": "Up", "datasource": {"uid": "${ds}"}, "gridPos": {"x": 0, "y": 1, "w": 6, "h": 4},
+     "targets": [{"refId": "A", "expr": "pg_up", "range": true}]},
+    {"id": 3, "type": "timeseries", "title": "Connections", "datasource": {"uid": "${ds}"}, "gridPos": {"x": 6, "y": 1, "w": 18, "h": 8},
+     "fieldConfig": {"defaults": {"unit": "short"}},
+     "targets": [
+       {"refId": "A", "expr": "sum(pg_stat_activity_count{datname=~\"$db\"}) by (state)", "legendFormat": "{{state}}", "range": true},
+       {"refId": "max", "expr": "pg_settings_max_connections"},
+       {"refId": "C", "expr": "pg_stat_activity_max_tx_duration", "range": true}
+     ]},
+    {"id": 4, "type": "table", "title": "Sizes", "datasource": {"uid": "${ds}"}, "gridPos": {"x": 0, "y": 9, "w": 24, "h": 8}},
+    {"id": 5, "type": "row", "title": "Details", "collapsed": true, "gridPos": {"x": 0, "y": 17, "w": 24, "h": 1}, "panels": [
+      {"id": 6, "type": "text", "title": "Notes", "datasource": {"uid": "${ds}"}, "gridPos": {"x": 0, "y": 18, "w": 12, "h": 8},
+       "options": {"mode": "markdown", "content": "# Runbook"}},
+      {"id": 7, "type": "timeseries", "title": "Locks", "datasource": {"uid": "${ds}"}, "repeat": "db", "gridPos": {"x": 12, "y": 18, "w": 12, "h": 8}}
+    ]},
+    {"id": 8, "type": "row", "title": "Logs", "gridPos": {"x": 0, "y": 18, "w": 24, "h": 1}},
+    {"id": 9, "type": "logs", "title": "Errors", "datasource": {"uid": "${ds}"}, "gridPos": {"x": 0, "y": 19, "w": 24, "h": 8}}
+  ]
+}`
+	got, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !testutil.JSONEqual(t, got, []byte(want)) {
+		t.Errorf("Build() got = %s, want %s", got, want)
+	}
+	if findings := lint.Dashboard(d, lint.Options{}); len(findings) > 0 {
+		t.Errorf("Build() got lint findings %v", findings)
+	}
+}
+
+func TestDashboardBuilder_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *DashboardBuilder
+		wantErr string
+	}{
+		{
+			name:    "Missing title",
+			builder: NewDashboard(""),
+			wantErr: "missing title",
+		},
+		{
+			name:    "Query without panel",
+			builder: NewDashboard("A").AddRow("Row").AddQuery(PrometheusQuery("up", "")),
+			wantErr: "query added before any panel",
+		},
+		{
+			name:    "Duplicate refId",
+			builder: NewDashboard("A").AddStat("Up").AddQuery(sdk.Target{RefID: "A"}).AddQuery(sdk.Target{RefID: "A"}),
+			wantErr: `panel "Up" already has a query A`,
+		},
+		{
+			name:    "Duplicate variable",
+			builder: NewDashboard("A").AddVariable(CustomVariable("env", "dev")).AddVariable(CustomVariable("env", "prod")),
+			wantErr: `variable "env" is already defined`,
+		},
+		{
+			name:    "First error wins",
+			builder: NewDashboard("A").AddStat("Wide", Width(30)).AddStat("Flat", Height(-1)),
+			wantErr: `panel "Wide" must be 1 to 24 columns wide, got 30`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Build() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDashboardBuilder_GrafanaDashboard(t *testing.T) {
+	db, err := NewDashboard("Nodes").
+		WithUID("nodes").
+		AddVariable(CustomVariable("env", "dev", "prod")).
+		AddTimeseries("CPU").
+		GrafanaDashboard("infra")
+	if err != nil {
+		t.Fatalf("GrafanaDashboard() error = %v", err)
+	}
+	if db.FolderUid != "infra" || !db.Overwrite {
+		t.Errorf("GrafanaDashboard() got folder = %q, overwrite = %v", db.FolderUid, db.Overwrite)
+	}
+	d, err := sdk.DashboardFromRawExtension(db.Dashboard)
+	if err != nil {
+		t.Fatalf("DashboardFromRawExtension() error = %v", err)
+	}
+	v := d.Templating.List[0]
+	if v.Query != "dev,prod" || v.Current == nil || v.Current.Value.String() != "dev" || len(v.Options) != 2 || !v.Options[0].Selected {
+		t.Errorf("GrafanaDashboard() got variable %+v", v)
+	}
+	if len(d.Panels) != 1 || d.Panels[0].ID != 1 || d.Panels[0].GridPos != (sdk.GridPos{W: layout.DefaultWidth, H: layout.DefaultHeight}) {
+		t.Errorf("GrafanaDashboard() got panels %+v", d.Panels)
+	}
+}
+
+func TestRefID(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := refID(i); got != want {
+			t.Errorf("refID(%d) got = %v, want %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"strings"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+// Values of Variable.Refresh.
+const (
+	RefreshOnDashboardLoad   = 1
+	RefreshOnTimeRangeChange = 2
+)
+
+// DatasourceVariable returns a variable selecting one of the datasources of the given plugin type,
+// e.g. prometheus. Reference it in WithDatasource as ${name}.
+func DatasourceVariable(name, pluginType string) sdk.Variable {
+	return sdk.Variable{
+		Name:  name,
+		Type:  "datasource",
+		Query: pluginType,
+	}
+}
+
+// QueryVariable returns a variable whose values are returned by a query of the datasource, e.g.
+// label_values(pg_up, instance). The values are refreshed when the dashboard is loaded.
+func QueryVariable(name string, datasource *sdk.DataSourceRef, query string) sdk.Variable {
+	return sdk.Variable{
+		Name:       name,
+		Type:       "query",
+		Datasource: datasource,
+		Query:      query,
+		Definition: query,
+		Refresh:    RefreshOnDashboardLoad,
+	}
+}
+
+// CustomVariable returns a variable with a fixed list of values. The first value is selected.
+func CustomVariable(name string, values ...string) sdk.Variable {
+	v := sdk.Variable{
+		Name:  name,
+		Type:  "custom",
+		Query: strings.Join(values, ","),
+	}
+	for i, value := range values {
+		v.Options = append(v.Options, sdk.VariableOption{
+			Selected: i == 0,
+			Text:     sdk.NewVariableValue(value),
+			Value:    sdk.NewVariableValue(value),
+		})
+	}
+	if len(v.Options) > 0 {
+		current := v.Options[0]
+		v.Current = &current
+	}
+	return v
+}
+
+// MultiValue allows selecting several values of the variable, as well as all of them.
+func MultiValue(v sdk.Variable) sdk.Variable {
+	v.Multi = true
+	v.IncludeAll = true
+	return v
+}
+
+// PrometheusQuery returns a Prometheus range query with the given legend, e.g. {{instance}}.
+func PrometheusQuery(expr, legendFormat string) sdk.Target {
+	return sdk.Target{
+		Expr:         expr,
+		LegendFormat: legendFormat,
+		Range:        true,
+	}
+}
+
+// LokiQuery returns a Loki query, e.g. {app="postgres"} |= "error".
+func LokiQuery(expr string) sdk.Target {
+	return sdk.Target{
+		Expr: expr,
+	}
+}
+
+// SQLQuery returns a raw SQL query of a PostgreSQL or MySQL datasource, returned in the given
+// format, time_series or table.
+func SQLQuery(rawSQL, format string) sdk.Target {
+	return sdk.Target{
+		RawSQL: rawSQL,
+		Format: format,
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DashboardWithMeta is a dashboard model together with the metadata Grafana keeps about it.
+type DashboardWithMeta struct {
+	Dashboard *runtime.RawExtension `json:"dashboard,omitempty"`
+	Meta      DashboardMeta         `json:"meta"`
+}
+
+// DashboardMeta as described in the doc
+// https://grafana.com/docs/grafana/latest/developers/http_api/dashboard/#get-dashboard-by-uid
+type DashboardMeta struct {
+	Type                  string    `json:"type,omitempty"`
+	Slug                  string    `json:"slug,omitempty"`
+	URL                   string    `json:"url,omitempty"`
+	Version               int       `json:"version,omitempty"`
+	CanSave               bool      `json:"canSave"`
+	CanEdit               bool      `json:"canEdit"`
+	CanAdmin              bool      `json:"canAdmin"`
+	CanStar               bool      `json:"canStar"`
+	CanDelete             bool      `json:"canDelete"`
+	IsStarred             bool      `json:"isStarred"`
+	IsFolder              bool      `json:"isFolder"`
+	HasACL                bool      `json:"hasAcl"`
+	Expires               time.Time `json:"expires"`
+	Created               time.Time `json:"created"`
+	Updated               time.Time `json:"updated"`
+	CreatedBy             string    `json:"createdBy,omitempty"`
+	UpdatedBy             string    `json:"updatedBy,omitempty"`
+	FolderID              int       `json:"folderId"`
+	FolderUID             string    `json:"folderUid,omitempty"`
+	FolderTitle           string    `json:"folderTitle,omitempty"`
+	FolderURL             string    `json:"folderUrl,omitempty"`
+	Provisioned           bool      `json:"provisioned"`
+	ProvisionedExternalID string    `json:"provisionedExternalId,omitempty"`
+}
+
+// GetDashboardByUID returns the dashboard with the given uid along with its metadata.
+// If the dashboard does not exist, the returned error matches ErrNotFound.
+// It reflects GET /api/dashboards/uid/:uid API call.
+func (c *Client) GetDashboardByUID(ctx context.Context, uid string) (*DashboardWithMeta, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/dashboards/uid", uid)
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	db := &DashboardWithMeta{}
+	if err = decodeResponse(resp, db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
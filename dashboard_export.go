@@ -0,0 +1,300 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// requiresKey is the member of a shared dashboard listing the plugins it depends on.
+const requiresKey = "__requires"
+
+// DashboardRequirement is an entry of the __requires of a dashboard exported for sharing.
+type DashboardRequirement struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ExportDatasource describes a datasource referenced by a dashboard that is exported for sharing.
+type ExportDatasource struct {
+	UID  string
+	Name string
+	// Type is the plugin id of the datasource, e.g. prometheus.
+	Type string
+	// PluginName is the display name of the plugin. It defaults to the name of well known plugins
+	// or the plugin id.
+	PluginName string
+}
+
+// ExportOptions configures ExportForSharing.
+type ExportOptions struct {
+	// GrafanaVersion is recorded as the Grafana version the dashboard requires.
+	GrafanaVersion string
+	// Datasources are used to look up the datasources the dashboard references by uid or name.
+	// References that are not found are exported by their uid, which requires them to carry a type.
+	Datasources []ExportDatasource
+}
+
+// pluginNames are the display names of core plugins.
+var pluginNames = map[string]string{
+	"alertlist":                   "Alert list",
+	"barchart":                    "Bar chart",
+	"bargauge":                    "Bar gauge",
+	"dashlist":                    "Dashboard list",
+	"gauge":                       "Gauge",
+	"graph":                       "Graph (old)",
+	"heatmap":                     "Heatmap",
+	"histogram":                   "Histogram",
+	"logs":                        "Logs",
+	"news":                        "News",
+	"nodeGraph":                   "Node Graph",
+	"piechart":                    "Pie chart",
+	"singlestat":                  "Singlestat",
+	"stat":                        "Stat",
+	"state-timeline":              "State timeline",
+	"status-history":              "Status history",
+	"table":                       "Table",
+	"table-old":                   "Table (old)",
+	"text":                        "Text",
+	"timeseries":                  "Time series",
+	"cloudwatch":                  "CloudWatch",
+	"elasticsearch":               "Elasticsearch",
+	"grafana-testdata-datasource": "TestData",
+	"graphite":                    "Graphite",
+	"influxdb":                    "InfluxDB",
+	"jaeger":                      "Jaeger",
+	"loki":                        "Loki",
+	"mssql":                       "Microsoft SQL Server",
+	"mysql":                       "MySQL",
+	"postgres":                    "PostgreSQL",
+	"prometheus":                  "Prometheus",
+	"tempo":                       "Tempo",
+	"zipkin":                      "Zipkin",
+}
+
+func pluginName(id string) string {
+	if name, ok := pluginNames[id]; ok {
+		return name
+	}
+	return id
+}
+
+// inputName turns s into the name of a dashboard input such as DS_PROMETHEUS.
+func inputName(prefix, s string) string {
+	return prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+type exporter struct {
+	opts     ExportOptions
+	inputs   []DashboardInput
+	requires map[string]DashboardRequirement
+}
+
+// ExportForSharing returns a copy of the dashboard prepared for importing into another Grafana, like the
+// "Export for sharing externally" option of Grafana does: references to concrete datasources are replaced
+// by ${DS_<NAME>} inputs, constant variables by ${VAR_<NAME>} inputs, the __inputs and __requires blocks
+// are added and id, uid and version are removed. References to template variables, the built-in
+// datasources and the default datasource are left untouched.
+func ExportForSharing(d *Dashboard, opts ExportOptions) (*Dashboard, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	out := &Dashboard{}
+	if err = json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+
+	e := &exporter{opts: opts, requires: map[string]DashboardRequirement{}}
+	e.require("grafana", "grafana", "Grafana", opts.GrafanaVersion)
+	for _, p := range out.AllPanels() {
+		if err = e.exportPanel(p); err != nil {
+			return nil, err
+		}
+	}
+	if out.Templating != nil {
+		for i := range out.Templating.List {
+			if err = e.exportVariable(&out.Templating.List[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if out.Annotations != nil {
+		for i := range out.Annotations.List {
+			if err = e.exportDatasource(out.Annotations.List[i].Datasource); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	out.ID, out.UID, out.Version = 0, "", 0
+	for _, key := range []string{"id", "uid", "version"} {
+		delete(out.preserved, key)
+	}
+	if out.Extra == nil {
+		out.Extra = map[string]json.RawMessage{}
+	}
+	inputs := e.inputs
+	if inputs == nil {
+		inputs = []DashboardInput{}
+	}
+	if out.Extra[inputsKey], err = json.Marshal(inputs); err != nil {
+		return nil, err
+	}
+	requires := make([]DashboardRequirement, 0, len(e.requires))
+	for _, r := range e.requires {
+		requires = append(requires, r)
+	}
+	sort.Slice(requires, func(i, j int) bool {
+		if requires[i].ID != requires[j].ID {
+			return requires[i].ID < requires[j].ID
+		}
+		return requires[i].Type < requires[j].Type
+	})
+	if out.Extra[requiresKey], err = json.Marshal(requires); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (e *exporter) require(typ, id, name, version string) {
+	key := typ + "/" + id
+	if _, ok := e.requires[key]; !ok {
+		e.requires[key] = DashboardRequirement{Type: typ, ID: id, Name: name, Version: version}
+	}
+}
+
+func (e *exporter) addInput(in DashboardInput) {
+	for _, existing := range e.inputs {
+		if existing.Name == in.Name {
+			return
+		}
+	}
+	e.inputs = append(e.inputs, in)
+}
+
+func (e *exporter) lookupDatasource(ref *DataSourceRef) (ExportDatasource, error) {
+	for _, ds := range e.opts.Datasources {
+		if (ref.UID != "" && ds.UID == ref.UID) || (ref.Name != "" && ds.Name == ref.Name) {
+			if ds.Type == "" {
+				ds.Type = ref.Type
+			}
+			if ds.Name == "" {
+				ds.Name = ds.UID
+			}
+			return ds, nil
+		}
+	}
+	if ref.Type == "" || ref.UID == "" {
+		return ExportDatasource{}, fmt.Errorf("failed to export dashboard, reason: unknown datasource %q", ref.Name+ref.UID)
+	}
+	return ExportDatasource{UID: ref.UID, Name: ref.UID, Type: ref.Type}, nil
+}
+
+// exportDatasource replaces the datasource referenced by ref with an input.
+func (e *exporter) exportDatasource(ref *DataSourceRef) error {
+	if ref == nil || ref.IsVariable() || ref.IsBuiltin() {
+		return nil
+	}
+	ds, err := e.lookupDatasource(ref)
+	if err != nil {
+		return err
+	}
+	if ds.PluginName == "" {
+		ds.PluginName = pluginName(ds.Type)
+	}
+	name := inputName("DS_", ds.Name)
+	e.addInput(DashboardInput{
+		Name:       name,
+		Label:      ds.Name,
+		Type:       InputTypeDatasource,
+		PluginID:   ds.Type,
+		PluginName: ds.PluginName,
+	})
+	e.require("datasource", ds.Type, ds.PluginName, "1.0.0")
+
+	if ref.UID == "" {
+		*ref = DataSourceRef{Name: "${" + name + "}"}
+	} else {
+		*ref = DataSourceRef{Type: ds.Type, UID: "${" + name + "}"}
+	}
+	return nil
+}
+
+func (e *exporter) exportPanel(p *Panel) error {
+	if !p.IsRow() && p.Type != "" {
+		e.require("panel", p.Type, pluginName(p.Type), p.PluginVersion)
+	}
+	if err := e.exportDatasource(p.Datasource); err != nil {
+		return err
+	}
+	for i := range p.Targets {
+		if err := e.exportDatasource(p.Targets[i].Datasource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportVariable(v *Variable) error {
+	switch v.Type {
+	case "query":
+		if err := e.exportDatasource(v.Datasource); err != nil {
+			return err
+		}
+		// The options are loaded from the datasource of the importing Grafana.
+		v.Options = nil
+		v.Current = &VariableOption{}
+		if v.Refresh == 0 {
+			v.Refresh = 1
+		}
+		delete(v.preserved, "options")
+		delete(v.preserved, "current")
+	case "constant":
+		value, _ := v.Query.(string)
+		label := v.Label
+		if label == "" {
+			label = v.Name
+		}
+		name := inputName("VAR_", v.Name)
+		e.addInput(DashboardInput{
+			Name:  name,
+			Label: label,
+			Type:  InputTypeConstant,
+			Value: value,
+		})
+		// The current value is resolved along with the query on import.
+		ref := "${" + name + "}"
+		v.Query = ref
+		v.Current = &VariableOption{Text: NewVariableValue(ref), Value: NewVariableValue(ref)}
+		v.Options = []VariableOption{*v.Current}
+	}
+	return nil
+}
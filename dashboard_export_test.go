@@ -0,0 +1,159 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const localDashboard = `{
+  "id": 12,
+  "uid": "pg",
+  "version": 7,
+  "title": "Postgres",
+  "annotations": {"list": [{"builtIn": 1, "datasource": {"type": "grafana", "uid": "-- Grafana --"}, "name": "Annotations & Alerts"}]},
+  "templating": {"list": [
+    {"name": "job", "type": "constant", "query": "postgres"},
+    {"name": "instance", "type": "query", "datasource": {"type": "prometheus", "uid": "P1"}, "query": "label_values(pg_up, instance)", "current": {"text": "db-0", "value": "db-0"}, "options": [{"text": "db-0", "value": "db-0"}]}
+  ]},
+  "panels": [
+    {"id": 1, "type": "row", "title": "General", "collapsed": true, "panels": [
+      {"id": 2, "type": "timeseries", "datasource": {"type": "prometheus", "uid": "P1"}, "targets": [{"refId": "A", "datasource": {"type": "prometheus", "uid": "P1"}, "expr": "pg_up"}]}
+    ]},
+    {"id": 3, "type": "logs", "pluginVersion": "10.4.0", "datasource": "Loki Prod", "targets": [{"refId": "A", "expr": "{job=\"$job\"}"}]},
+    {"id": 4, "type": "stat", "datasource": "${datasource}"}
+  ]
+}`
+
+func TestExportForSharing(t *testing.T) {
+	d := &Dashboard{}
+	if err := json.Unmarshal([]byte(localDashboard), d); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	got, err := ExportForSharing(d, ExportOptions{
+		GrafanaVersion: "10.4.0",
+		Datasources: []ExportDatasource{
+			{UID: "P1", Name: "Prometheus", Type: "prometheus"},
+			{UID: "L1", Name: "Loki Prod", Type: "loki"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExportForSharing() error = %v", err)
+	}
+	if d.UID != "pg" || d.Panels[1].Datasource.Name != "Loki Prod" {
+		t.Errorf("ExportForSharing() modified its argument")
+	}
+	if got.ID != 0 || got.UID != "" || got.Version != 0 {
+		t.Errorf("ExportForSharing() kept id = %v, uid = %v, version = %v", got.ID, got.UID, got.Version)
+	}
+
+	var inputs []DashboardInput
+	if err = json.Unmarshal(got.Extra[inputsKey], &inputs); err != nil {
+		t.Fatalf("Unmarshal(__inputs) error = %v", err)
+	}
+	wantInputs := []DashboardInput{
+		{Name: "DS_PROMETHEUS", Label: "Prometheus", Type: InputTypeDatasource, PluginID: "prometheus", PluginName: "Prometheus"},
+		{Name: "DS_LOKI_PROD", Label: "Loki Prod", Type: InputTypeDatasource, PluginID: "loki", PluginName: "Loki"},
+		{Name: "VAR_JOB", Label: "job", Type: InputTypeConstant, Value: "postgres"},
+	}
+	if !reflect.DeepEqual(inputs, wantInputs) {
+		t.Errorf("__inputs got = %+v, want %+v", inputs, wantInputs)
+	}
+
+	var requires []DashboardRequirement
+	if err = json.Unmarshal(got.Extra[requiresKey], &requires); err != nil {
+		t.Fatalf("Unmarshal(__requires) error = %v", err)
+	}
+	wantRequires := []DashboardRequirement{
+		{Type: "grafana", ID: "grafana", Name: "Grafana", Version: "10.4.0"},
+		{Type: "panel", ID: "logs", Name: "Logs", Version: "10.4.0"},
+		{Type: "datasource", ID: "loki", Name: "Loki", Version: "1.0.0"},
+		{Type: "datasource", ID: "prometheus", Name: "Prometheus", Version: "1.0.0"},
+		{Type: "panel", ID: "stat", Name: "Stat"},
+		{Type: "panel", ID: "timeseries", Name: "Time series"},
+	}
+	if !reflect.DeepEqual(requires, wantRequires) {
+		t.Errorf("__requires got = %+v, want %+v", requires, wantRequires)
+	}
+
+	// Importing the exported dashboard restores the datasource references.
+	raw, err := got.RawExtension()
+	if err != nil {
+		t.Fatalf("RawExtension() error = %v", err)
+	}
+	raw, err = ResolveDashboardInputs(raw, []ImportInput{
+		DatasourceInput("DS_PROMETHEUS", "prometheus", "P1"),
+		DatasourceInput("DS_LOKI_PROD", "loki", "L1"),
+		ConstantInput("VAR_JOB", "postgres"),
+	})
+	if err != nil {
+		t.Fatalf("ResolveDashboardInputs() error = %v", err)
+	}
+	imported, err := DashboardFromRawExtension(raw)
+	if err != nil {
+		t.Fatalf("DashboardFromRawExtension() error = %v", err)
+	}
+	panels := imported.AllPanels()
+	if *panels[1].Datasource != (DataSourceRef{Type: "prometheus", UID: "P1"}) ||
+		*panels[1].Targets[0].Datasource != (DataSourceRef{Type: "prometheus", UID: "P1"}) ||
+		*panels[2].Datasource != (DataSourceRef{Name: "L1"}) ||
+		*panels[3].Datasource != (DataSourceRef{Name: "${datasource}"}) {
+		t.Errorf("imported datasources got = %+v, %+v, %+v", panels[1].Datasource, panels[2].Datasource, panels[3].Datasource)
+	}
+	vars := imported.Templating.List
+	if vars[0].Query != "postgres" || vars[0].Current.Value.String() != "postgres" {
+		t.Errorf("imported constant variable got = %+v", vars[0])
+	}
+	if *vars[1].Datasource != (DataSourceRef{Type: "prometheus", UID: "P1"}) || len(vars[1].Options) != 0 || vars[1].Refresh != 1 {
+		t.Errorf("imported query variable got = %+v", vars[1])
+	}
+	if imported.Annotations.List[0].Datasource.UID != "-- Grafana --" {
+		t.Errorf("imported annotation got = %+v", imported.Annotations.List[0])
+	}
+}
+
+func TestExportForSharing_UnknownDatasource(t *testing.T) {
+	d := &Dashboard{Panels: []Panel{{ID: 1, Type: "stat", Datasource: &DataSourceRef{Name: "Prometheus"}}}}
+	if _, err := ExportForSharing(d, ExportOptions{}); err == nil {
+		t.Errorf("ExportForSharing() error = nil, want unknown datasource")
+	}
+}
+
+func TestExportForSharing_Testdata(t *testing.T) {
+	data, err := os.ReadFile("./testdata/dashboard.yaml")
+	if err != nil {
+		t.Fatalf("failed to read json model, reason: %v", err)
+	}
+	d := &Dashboard{}
+	if err = json.Unmarshal(data, d); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	got, err := ExportForSharing(d, ExportOptions{GrafanaVersion: "8.2.0"})
+	if err != nil {
+		t.Fatalf("ExportForSharing() error = %v", err)
+	}
+	raw, err := got.RawExtension()
+	if err != nil {
+		t.Fatalf("RawExtension() error = %v", err)
+	}
+	if _, err = ResolveDashboardInputs(raw, nil); err != nil {
+		t.Errorf("ResolveDashboardInputs() error = %v", err)
+	}
+}
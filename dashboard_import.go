@@ -0,0 +1,201 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// InputType is the type of a dashboard input.
+type InputType string
+
+const (
+	InputTypeDatasource InputType = "datasource"
+	InputTypeConstant   InputType = "constant"
+)
+
+// inputsKey is the member of a shared dashboard listing its inputs.
+const inputsKey = "__inputs"
+
+// DashboardInput is an entry of the __inputs of a dashboard exported for sharing, e.g. from grafana.com.
+// The dashboard refers to it as ${Name}.
+type DashboardInput struct {
+	Name        string    `json:"name"`
+	Label       string    `json:"label,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Type        InputType `json:"type"`
+	PluginID    string    `json:"pluginId,omitempty"`
+	PluginName  string    `json:"pluginName,omitempty"`
+	// Value is the default value of a constant input.
+	Value string `json:"value,omitempty"`
+}
+
+// ImportInput supplies the value of a dashboard input on import.
+type ImportInput struct {
+	Name     string    `json:"name"`
+	Type     InputType `json:"type"`
+	PluginID string    `json:"pluginId,omitempty"`
+	Value    string    `json:"value"`
+}
+
+// DatasourceInput returns the input mapping the datasource input name, e.g. DS_PROMETHEUS,
+// to the datasource with the given uid.
+func DatasourceInput(name, pluginID, uid string) ImportInput {
+	return ImportInput{Name: name, Type: InputTypeDatasource, PluginID: pluginID, Value: uid}
+}
+
+// ConstantInput returns the input setting the constant input name to value.
+func ConstantInput(name, value string) ImportInput {
+	return ImportInput{Name: name, Type: InputTypeConstant, Value: value}
+}
+
+// ImportDashboardRequest as described in the doc
+// https://grafana.com/docs/grafana/latest/developers/http_api/dashboard/#import-dashboard
+type ImportDashboardRequest struct {
+	Dashboard *runtime.RawExtension `json:"dashboard"`
+	Inputs    []ImportInput         `json:"inputs"`
+	FolderUID string                `json:"folderUid,omitempty"`
+	Overwrite bool                  `json:"overwrite"`
+}
+
+// ImportDashboardResponse is returned by ImportDashboard.
+type ImportDashboardResponse struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Slug        string `json:"slug,omitempty"`
+	DashboardID int    `json:"dashboardId"`
+	FolderID    int    `json:"folderId"`
+	FolderUID   string `json:"folderUid,omitempty"`
+	Imported    bool   `json:"imported"`
+	ImportedURL string `json:"importedUrl,omitempty"`
+	Revision    int    `json:"revision,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ImportDashboard imports a dashboard exported for sharing, substituting its __inputs with req.Inputs.
+// It reflects POST /api/dashboards/import API call.
+func (c *Client) ImportDashboard(ctx context.Context, req ImportDashboardRequest) (*ImportDashboardResponse, error) {
+	if req.Dashboard == nil {
+		return nil, errors.New("failed to import dashboard, reason: missing dashboard model")
+	}
+	if req.Inputs == nil {
+		req.Inputs = []ImportInput{}
+	}
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/dashboards/import")
+	resp, err := c.do(ctx, http.MethodPost, u.String(), req)
+	if err != nil {
+		return nil, err
+	}
+	out := &ImportDashboardResponse{}
+	if err = decodeResponse(resp, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DashboardInputs returns the __inputs of the dashboard model held by raw.
+func DashboardInputs(raw *runtime.RawExtension) ([]DashboardInput, error) {
+	if raw == nil {
+		return nil, errors.New("missing dashboard model")
+	}
+	data, err := raw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var model struct {
+		Inputs []DashboardInput `json:"__inputs"`
+	}
+	if err = json.Unmarshal(data, &model); err != nil {
+		return nil, err
+	}
+	return model.Inputs, nil
+}
+
+var inputRef = regexp.MustCompile(`\$\{.+?\}`)
+
+// ResolveDashboardInputs substitutes the __inputs of the dashboard model held by raw with inputs the same
+// way ImportDashboard does on the server, without calling Grafana. It is meant for dry runs and for
+// saving shared dashboards with SetDashboard. Every declared input must be matched by name and type.
+func ResolveDashboardInputs(raw *runtime.RawExtension, inputs []ImportInput) (*runtime.RawExtension, error) {
+	declared, err := DashboardInputs(raw)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, def := range declared {
+		found := false
+		for _, in := range inputs {
+			if in.Name == def.Name && in.Type == def.Type {
+				values["${"+def.Name+"}"] = in.Value
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("failed to resolve dashboard inputs, reason: missing %s input %s", def.Type, def.Name)
+		}
+	}
+
+	data, err := raw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var model any
+	if err = dec.Decode(&model); err != nil {
+		return nil, err
+	}
+	data, err = json.Marshal(resolveInputs(model, values))
+	if err != nil {
+		return nil, err
+	}
+	return &runtime.RawExtension{Raw: data}, nil
+}
+
+func resolveInputs(v any, values map[string]string) any {
+	switch v := v.(type) {
+	case string:
+		return inputRef.ReplaceAllStringFunc(v, func(ref string) string {
+			if value, ok := values[ref]; ok {
+				return value
+			}
+			return ref
+		})
+	case []any:
+		for i := range v {
+			v[i] = resolveInputs(v[i], values)
+		}
+	case map[string]any:
+		delete(v, inputsKey)
+		for k := range v {
+			v[k] = resolveInputs(v[k], values)
+		}
+	}
+	return v
+}
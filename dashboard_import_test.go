@@ -0,0 +1,113 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.openviz.dev/grafana-sdk/internal/testutil"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const sharedDashboard = `{
+  "__inputs": [
+    {"name": "DS_PROMETHEUS", "label": "Prometheus", "type": "datasource", "pluginId": "prometheus", "pluginName": "Prometheus"},
+    {"name": "VAR_JOB", "label": "Job", "type": "constant", "value": "postgres"}
+  ],
+  "gnetId": 9628,
+  "title": "PostgreSQL Database",
+  "panels": [
+    {"id": 1, "datasource": "${DS_PROMETHEUS}", "targets": [{"refId": "A", "expr": "pg_up{job=\"${VAR_JOB}\", instance=\"$instance\"}"}]},
+    {"id": 2, "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"}, "maxDataPoints": 1e3, "description": "${DS_OTHER}"}
+  ]
+}`
+
+func TestResolveDashboardInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  []ImportInput
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "All inputs",
+			inputs: []ImportInput{DatasourceInput("DS_PROMETHEUS", "prometheus", "P1"), ConstantInput("VAR_JOB", "pg")},
+			want: `{
+  "gnetId": 9628,
+  "title": "PostgreSQL Database",
+  "panels": [
+    {"id": 1, "datasource": "P1", "targets": [{"refId": "A", "expr": "pg_up{job=\"pg\", instance=\"$instance\"}"}]},
+    {"id": 2, "datasource": {"type": "prometheus", "uid": "P1"}, "maxDataPoints": 1e3, "description": "${DS_OTHER}"}
+  ]
+}`,
+		},
+		{
+			name:    "Missing input",
+			inputs:  []ImportInput{DatasourceInput("DS_PROMETHEUS", "prometheus", "P1")},
+			wantErr: true,
+		},
+		{
+			name:    "Mismatched input type",
+			inputs:  []ImportInput{ConstantInput("DS_PROMETHEUS", "P1"), ConstantInput("VAR_JOB", "pg")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveDashboardInputs(&runtime.RawExtension{Raw: []byte(sharedDashboard)}, tt.inputs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveDashboardInputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !testutil.JSONEqual(t, got.Raw, []byte(tt.want)) {
+				t.Errorf("ResolveDashboardInputs() got = %s, want %s", got.Raw, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ImportDashboard(t *testing.T) {
+	srv, reqs := newRecordingServer(t, http.StatusOK, `{"uid":"pg","title":"PostgreSQL Database","dashboardId":7,"folderUid":"db","imported":true,"importedUrl":"/d/pg/postgresql-database"}`)
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	got, err := c.ImportDashboard(context.TODO(), ImportDashboardRequest{
+		Dashboard: &runtime.RawExtension{Raw: []byte(`{"title":"PostgreSQL Database"}`)},
+		Inputs:    []ImportInput{DatasourceInput("DS_PROMETHEUS", "prometheus", "P1")},
+		FolderUID: "db",
+		Overwrite: true,
+	})
+	if err != nil {
+		t.Fatalf("ImportDashboard() error = %v", err)
+	}
+	if !got.Imported || got.UID != "pg" || got.DashboardID != 7 || got.FolderUID != "db" {
+		t.Errorf("ImportDashboard() got = %+v", got)
+	}
+	wantBody := `{"dashboard":{"title":"PostgreSQL Database"},"inputs":[{"name":"DS_PROMETHEUS","type":"datasource","pluginId":"prometheus","value":"P1"}],"folderUid":"db","overwrite":true}`
+	if len(*reqs) != 1 || (*reqs)[0].Method != http.MethodPost || (*reqs)[0].Path != "/api/dashboards/import" || !testutil.JSONEqual(t, []byte((*reqs)[0].Body), []byte(wantBody)) {
+		t.Errorf("ImportDashboard() requests = %+v", *reqs)
+	}
+	if _, err = c.ImportDashboard(context.TODO(), ImportDashboardRequest{}); err == nil {
+		t.Errorf("ImportDashboard() without dashboard error = nil")
+	}
+}
@@ -0,0 +1,591 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Dashboard is the JSON model of a Grafana dashboard as described in the doc
+// https://grafana.com/docs/grafana/latest/dashboards/build-dashboards/view-dashboard-json-model/
+// Members that are not modelled are kept in Extra, so decoding and encoding a dashboard is lossless.
+type Dashboard struct {
+	ID            int             `json:"id,omitempty"`
+	UID           string          `json:"uid,omitempty"`
+	Title         string          `json:"title,omitempty"`
+	Description   string          `json:"description,omitempty"`
+	Tags          []string        `json:"tags,omitempty"`
+	Editable      bool            `json:"editable,omitempty"`
+	GraphTooltip  int             `json:"graphTooltip,omitempty"`
+	Timezone      string          `json:"timezone,omitempty"`
+	Time          *TimeRange      `json:"time,omitempty"`
+	Timepicker    json.RawMessage `json:"timepicker,omitempty"`
+	Refresh       string          `json:"refresh,omitempty"`
+	SchemaVersion int             `json:"schemaVersion,omitempty"`
+	Version       int             `json:"version,omitempty"`
+	Links         []Link          `json:"links,omitempty"`
+	Annotations   *Annotations    `json:"annotations,omitempty"`
+	Templating    *Templating     `json:"templating,omitempty"`
+	Panels        []Panel         `json:"panels,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// TimeRange is the default time range of a dashboard.
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Panel is a dashboard panel. Row panels hold their collapsed child panels in Panels.
+type Panel struct {
+	ID              int                `json:"id,omitempty"`
+	Type            string             `json:"type,omitempty"`
+	Title           string             `json:"title,omitempty"`
+	Description     string             `json:"description,omitempty"`
+	Datasource      *DataSourceRef     `json:"datasource,omitempty"`
+	GridPos         GridPos            `json:"gridPos,omitzero"`
+	Collapsed       bool               `json:"collapsed,omitempty"`
+	Panels          []Panel            `json:"panels,omitempty"`
+	Targets         []Target           `json:"targets,omitempty"`
+	FieldConfig     *FieldConfigSource `json:"fieldConfig,omitempty"`
+	Options         map[string]any     `json:"options,omitempty"`
+	Transformations []Transformation   `json:"transformations,omitempty"`
+	Links           []Link             `json:"links,omitempty"`
+	Interval        string             `json:"interval,omitempty"`
+	MaxDataPoints   int                `json:"maxDataPoints,omitempty"`
+	TimeFrom        string             `json:"timeFrom,omitempty"`
+	TimeShift       string             `json:"timeShift,omitempty"`
+	Repeat          string             `json:"repeat,omitempty"`
+	RepeatDirection string             `json:"repeatDirection,omitempty"`
+	Transparent     bool               `json:"transparent,omitempty"`
+	PluginVersion   string             `json:"pluginVersion,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// GridPos is the position of a panel on the 24 column dashboard grid.
+type GridPos struct {
+	H      int  `json:"h"`
+	W      int  `json:"w"`
+	X      int  `json:"x"`
+	Y      int  `json:"y"`
+	Static bool `json:"static,omitempty"`
+}
+
+// DataSourceRef references a datasource. Dashboards with schemaVersion below 33 reference
+// datasources by name or by a variable such as "${datasource}"; such references are decoded
+// into Name and encoded back as a plain string.
+type DataSourceRef struct {
+	Type string `json:"type,omitempty"`
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"-"`
+}
+
+// Target is a query of a panel. Query fields of plugins that are not modelled are kept in Extra.
+type Target struct {
+	RefID          string         `json:"refId,omitempty"`
+	Datasource     *DataSourceRef `json:"datasource,omitempty"`
+	Hide           bool           `json:"hide,omitempty"`
+	Expr           string         `json:"expr,omitempty"`
+	LegendFormat   string         `json:"legendFormat,omitempty"`
+	Interval       string         `json:"interval,omitempty"`
+	IntervalFactor int            `json:"intervalFactor,omitempty"`
+	Format         string         `json:"format,omitempty"`
+	Instant        bool           `json:"instant,omitempty"`
+	Range          bool           `json:"range,omitempty"`
+	Exemplar       bool           `json:"exemplar,omitempty"`
+	Query          string         `json:"query,omitempty"`
+	RawSQL         string         `json:"rawSql,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// Templating holds the template variables of a dashboard.
+type Templating struct {
+	List []Variable `json:"list,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// Variable is a dashboard template variable.
+type Variable struct {
+	Name        string         `json:"name"`
+	Type        string         `json:"type,omitempty"`
+	Label       string         `json:"label,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Datasource  *DataSourceRef `json:"datasource,omitempty"`
+	// Query is a string or, for query variables of newer dashboards, an object.
+	Query       any              `json:"query,omitempty"`
+	Definition  string           `json:"definition,omitempty"`
+	Regex       string           `json:"regex,omitempty"`
+	Current     *VariableOption  `json:"current,omitempty"`
+	Options     []VariableOption `json:"options,omitempty"`
+	Multi       bool             `json:"multi,omitempty"`
+	IncludeAll  bool             `json:"includeAll,omitempty"`
+	AllValue    string           `json:"allValue,omitempty"`
+	Hide        int              `json:"hide,omitempty"`
+	Refresh     int              `json:"refresh,omitempty"`
+	Sort        int              `json:"sort,omitempty"`
+	SkipURLSync bool             `json:"skipUrlSync,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// VariableOption is a selectable or the selected value of a template variable.
+type VariableOption struct {
+	Selected bool          `json:"selected,omitempty"`
+	Text     VariableValue `json:"text,omitzero"`
+	Value    VariableValue `json:"value,omitzero"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// VariableValue is the text or value of a variable option. Single values are encoded as
+// a JSON string, multi-value selections as a JSON array of strings.
+type VariableValue struct {
+	Values []string
+	// IsList reports whether the value is encoded as an array, even if it holds a single element.
+	IsList bool
+}
+
+// Annotations holds the annotation queries of a dashboard.
+type Annotations struct {
+	List []Annotation `json:"list,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// Annotation is an annotation query.
+type Annotation struct {
+	Name       string         `json:"name,omitempty"`
+	Datasource *DataSourceRef `json:"datasource,omitempty"`
+	Enable     bool           `json:"enable,omitempty"`
+	Hide       bool           `json:"hide,omitempty"`
+	IconColor  string         `json:"iconColor,omitempty"`
+	BuiltIn    int            `json:"builtIn,omitempty"`
+	Type       string         `json:"type,omitempty"`
+	Expr       string         `json:"expr,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// Link is a dashboard or panel link.
+type Link struct {
+	Title       string   `json:"title,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Tooltip     string   `json:"tooltip,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	AsDropdown  bool     `json:"asDropdown,omitempty"`
+	IncludeVars bool     `json:"includeVars,omitempty"`
+	KeepTime    bool     `json:"keepTime,omitempty"`
+	TargetBlank bool     `json:"targetBlank,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// FieldConfigSource holds the field configuration of a panel.
+type FieldConfigSource struct {
+	Defaults  FieldConfig `json:"defaults"`
+	Overrides []Override  `json:"overrides,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// FieldConfig is the display configuration applied to the fields of a panel.
+type FieldConfig struct {
+	DisplayName string         `json:"displayName,omitempty"`
+	Unit        string         `json:"unit,omitempty"`
+	Decimals    *int           `json:"decimals,omitempty"`
+	Min         *float64       `json:"min,omitempty"`
+	Max         *float64       `json:"max,omitempty"`
+	NoValue     string         `json:"noValue,omitempty"`
+	Color       map[string]any `json:"color,omitempty"`
+	Thresholds  *Thresholds    `json:"thresholds,omitempty"`
+	Mappings    []any          `json:"mappings,omitempty"`
+	Links       []Link         `json:"links,omitempty"`
+	Custom      map[string]any `json:"custom,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// Thresholds configures the thresholds of a field.
+type Thresholds struct {
+	Mode  string      `json:"mode,omitempty"`
+	Steps []Threshold `json:"steps"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// Threshold is a threshold step. The base step has a nil Value.
+type Threshold struct {
+	Color string   `json:"color"`
+	Value *float64 `json:"value"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// Override overrides the field configuration of the fields selected by Matcher.
+type Override struct {
+	Matcher    Matcher            `json:"matcher"`
+	Properties []OverrideProperty `json:"properties"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// Matcher selects fields, e.g. by name or by query.
+type Matcher struct {
+	ID      string `json:"id"`
+	Options any    `json:"options,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// OverrideProperty sets a single field config property.
+type OverrideProperty struct {
+	ID    string `json:"id"`
+	Value any    `json:"value"`
+}
+
+// Transformation is a data transformation applied to the query results of a panel.
+type Transformation struct {
+	ID       string         `json:"id"`
+	Disabled bool           `json:"disabled,omitempty"`
+	Options  map[string]any `json:"options,omitempty"`
+
+	Extra     map[string]json.RawMessage `json:"-"`
+	preserved map[string]json.RawMessage
+}
+
+// DashboardFromRawExtension decodes the dashboard model held by raw.
+func DashboardFromRawExtension(raw *runtime.RawExtension) (*Dashboard, error) {
+	if raw == nil {
+		return nil, errors.New("missing dashboard model")
+	}
+	data, err := raw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	d := &Dashboard{}
+	if err = json.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// RawExtension encodes the dashboard into the form used by GrafanaDashboard.
+func (d *Dashboard) RawExtension() (*runtime.RawExtension, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return &runtime.RawExtension{Raw: data}, nil
+}
+
+// AllPanels returns pointers to all panels of the dashboard, including the panels nested
+// in collapsed rows, in document order.
+func (d *Dashboard) AllPanels() []*Panel {
+	var panels []*Panel
+	for i := range d.Panels {
+		panels = append(panels, &d.Panels[i])
+		for j := range d.Panels[i].Panels {
+			panels = append(panels, &d.Panels[i].Panels[j])
+		}
+	}
+	return panels
+}
+
+// IsRow reports whether the panel is a row.
+func (p *Panel) IsRow() bool {
+	return p.Type == "row"
+}
+
+func (d Dashboard) MarshalJSON() ([]byte, error) {
+	type plain Dashboard
+	return marshalModel((*plain)(&d), d.Extra, d.preserved)
+}
+
+func (d *Dashboard) UnmarshalJSON(data []byte) error {
+	type plain Dashboard
+	return unmarshalModel(data, (*plain)(d), &d.Extra, &d.preserved)
+}
+
+func (p Panel) MarshalJSON() ([]byte, error) {
+	type plain Panel
+	return marshalModel((*plain)(&p), p.Extra, p.preserved)
+}
+
+func (p *Panel) UnmarshalJSON(data []byte) error {
+	type plain Panel
+	return unmarshalModel(data, (*plain)(p), &p.Extra, &p.preserved)
+}
+
+func (t Target) MarshalJSON() ([]byte, error) {
+	type plain Target
+	return marshalModel((*plain)(&t), t.Extra, t.preserved)
+}
+
+func (t *Target) UnmarshalJSON(data []byte) error {
+	type plain Target
+	return unmarshalModel(data, (*plain)(t), &t.Extra, &t.preserved)
+}
+
+func (t Templating) MarshalJSON() ([]byte, error) {
+	type plain Templating
+	return marshalModel((*plain)(&t), t.Extra, t.preserved)
+}
+
+func (t *Templating) UnmarshalJSON(data []byte) error {
+	type plain Templating
+	return unmarshalModel(data, (*plain)(t), &t.Extra, &t.preserved)
+}
+
+func (v Variable) MarshalJSON() ([]byte, error) {
+	type plain Variable
+	return marshalModel((*plain)(&v), v.Extra, v.preserved)
+}
+
+func (v *Variable) UnmarshalJSON(data []byte) error {
+	type plain Variable
+	return unmarshalModel(data, (*plain)(v), &v.Extra, &v.preserved)
+}
+
+func (o VariableOption) MarshalJSON() ([]byte, error) {
+	type plain VariableOption
+	return marshalModel((*plain)(&o), o.Extra, o.preserved)
+}
+
+func (o *VariableOption) UnmarshalJSON(data []byte) error {
+	type plain VariableOption
+	return unmarshalModel(data, (*plain)(o), &o.Extra, &o.preserved)
+}
+
+func (a Annotations) MarshalJSON() ([]byte, error) {
+	type plain Annotations
+	return marshalModel((*plain)(&a), a.Extra, a.preserved)
+}
+
+func (a *Annotations) UnmarshalJSON(data []byte) error {
+	type plain Annotations
+	return unmarshalModel(data, (*plain)(a), &a.Extra, &a.preserved)
+}
+
+func (a Annotation) MarshalJSON() ([]byte, error) {
+	type plain Annotation
+	return marshalModel((*plain)(&a), a.Extra, a.preserved)
+}
+
+func (a *Annotation) UnmarshalJSON(data []byte) error {
+	type plain Annotation
+	return unmarshalModel(data, (*plain)(a), &a.Extra, &a.preserved)
+}
+
+func (l Link) MarshalJSON() ([]byte, error) {
+	type plain Link
+	return marshalModel((*plain)(&l), l.Extra, l.preserved)
+}
+
+func (l *Link) UnmarshalJSON(data []byte) error {
+	type plain Link
+	return unmarshalModel(data, (*plain)(l), &l.Extra, &l.preserved)
+}
+
+func (f FieldConfigSource) MarshalJSON() ([]byte, error) {
+	type plain FieldConfigSource
+	return marshalModel((*plain)(&f), f.Extra, f.preserved)
+}
+
+func (f *FieldConfigSource) UnmarshalJSON(data []byte) error {
+	type plain FieldConfigSource
+	return unmarshalModel(data, (*plain)(f), &f.Extra, &f.preserved)
+}
+
+func (f FieldConfig) MarshalJSON() ([]byte, error) {
+	type plain FieldConfig
+	return marshalModel((*plain)(&f), f.Extra, f.preserved)
+}
+
+func (f *FieldConfig) UnmarshalJSON(data []byte) error {
+	type plain FieldConfig
+	return unmarshalModel(data, (*plain)(f), &f.Extra, &f.preserved)
+}
+
+func (t Thresholds) MarshalJSON() ([]byte, error) {
+	type plain Thresholds
+	return marshalModel((*plain)(&t), t.Extra, t.preserved)
+}
+
+func (t *Thresholds) UnmarshalJSON(data []byte) error {
+	type plain Thresholds
+	return unmarshalModel(data, (*plain)(t), &t.Extra, &t.preserved)
+}
+
+func (t Threshold) MarshalJSON() ([]byte, error) {
+	type plain Threshold
+	return marshalModel((*plain)(&t), t.Extra, t.preserved)
+}
+
+func (t *Threshold) UnmarshalJSON(data []byte) error {
+	type plain Threshold
+	return unmarshalModel(data, (*plain)(t), &t.Extra, &t.preserved)
+}
+
+func (o Override) MarshalJSON() ([]byte, error) {
+	type plain Override
+	return marshalModel((*plain)(&o), o.Extra, o.preserved)
+}
+
+func (o *Override) UnmarshalJSON(data []byte) error {
+	type plain Override
+	return unmarshalModel(data, (*plain)(o), &o.Extra, &o.preserved)
+}
+
+func (m Matcher) MarshalJSON() ([]byte, error) {
+	type plain Matcher
+	return marshalModel((*plain)(&m), m.Extra, m.preserved)
+}
+
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	type plain Matcher
+	return unmarshalModel(data, (*plain)(m), &m.Extra, &m.preserved)
+}
+
+func (t Transformation) MarshalJSON() ([]byte, error) {
+	type plain Transformation
+	return marshalModel((*plain)(&t), t.Extra, t.preserved)
+}
+
+func (t *Transformation) UnmarshalJSON(data []byte) error {
+	type plain Transformation
+	return unmarshalModel(data, (*plain)(t), &t.Extra, &t.preserved)
+}
+
+func (r DataSourceRef) MarshalJSON() ([]byte, error) {
+	if r.Name != "" && r.Type == "" && r.UID == "" {
+		return json.Marshal(r.Name)
+	}
+	type plain DataSourceRef
+	return json.Marshal(plain(r))
+}
+
+func (r *DataSourceRef) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*r = DataSourceRef{Name: name}
+		return nil
+	}
+	type plain DataSourceRef
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// IsVariable reports whether the reference names a template variable such as "$ds" or "${ds}".
+func (r *DataSourceRef) IsVariable() bool {
+	if r == nil {
+		return false
+	}
+	for _, s := range []string{r.Name, r.UID} {
+		if len(s) > 0 && s[0] == '$' {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBuiltin reports whether the reference names the built-in Grafana, Mixed or Dashboard datasource,
+// which exist in every Grafana.
+func (r *DataSourceRef) IsBuiltin() bool {
+	switch {
+	case r == nil:
+		return false
+	case r.Type == "datasource" || r.Type == "grafana":
+		return true
+	case r.UID == "grafana" || r.UID == "-- Mixed --" || r.UID == "-- Dashboard --":
+		return true
+	case r.Name == "-- Grafana --" || r.Name == "-- Mixed --" || r.Name == "-- Dashboard --":
+		return true
+	}
+	return false
+}
+
+// NewVariableValue returns a single value encoded as a JSON string.
+func NewVariableValue(v string) VariableValue {
+	return VariableValue{Values: []string{v}}
+}
+
+// NewVariableValues returns a multi-value selection encoded as a JSON array.
+func NewVariableValues(v ...string) VariableValue {
+	return VariableValue{Values: v, IsList: true}
+}
+
+// String returns the single value or the values joined by " + ", the way Grafana displays them.
+func (v VariableValue) String() string {
+	return strings.Join(v.Values, " + ")
+}
+
+func (v VariableValue) MarshalJSON() ([]byte, error) {
+	if v.IsList || len(v.Values) != 1 {
+		if v.Values == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(v.Values)
+	}
+	return json.Marshal(v.Values[0])
+}
+
+func (v *VariableValue) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*v = VariableValue{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = NewVariableValue(s)
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*v = NewVariableValues(list...)
+	return nil
+}
+
+// Model decodes the typed dashboard model of the response.
+func (d *DashboardWithMeta) Model() (*Dashboard, error) {
+	return DashboardFromRawExtension(d.Dashboard)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"go.openviz.dev/grafana-sdk/internal/testutil"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestDashboard_RoundTrip(t *testing.T) {
+	testdata, err := os.ReadFile("./testdata/dashboard.yaml")
+	if err != nil {
+		t.Fatalf("failed to read json model, reason: %v", err)
+	}
+	tests := []struct {
+		name  string
+		model []byte
+	}{
+		{
+			name:  "Testdata dashboard",
+			model: testdata,
+		},
+		{
+			name:  "Legacy values and unknown fields",
+			model: []byte(`{"refresh":false,"iteration":123,"panels":[{"id":1,"type":"graph","datasource":null,"yaxes":[{"format":"short"}],"targets":[{"refId":"A","expr":"up","hide":false,"step":10}],"gridPos":{"h":8,"w":12,"x":0,"y":0},"options":{}}],"templating":{"list":[{"name":"ns","current":{"text":["a","b"],"value":["a","b"]},"allValue":null,"options":[]}]}}`),
+		},
+		{
+			name:  "Unknown threshold and matcher fields",
+			model: []byte(`{"panels":[{"id":1,"fieldConfig":{"defaults":{"thresholds":{"mode":"absolute","steps":[{"color":"green","value":null,"state":{}},{"color":"red","value":80}],"style":"line"}},"overrides":[{"matcher":{"id":"byName","options":"up","scope":"series"},"properties":[]}]}}]}`),
+		},
+		{
+			name:  "Datasource references",
+			model: []byte(`{"panels":[{"id":1,"datasource":{"type":"prometheus","uid":"P1"},"targets":[{"refId":"A","datasource":"${DS_PROMETHEUS}"}]}]}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := DashboardFromRawExtension(&runtime.RawExtension{Raw: tt.model})
+			if err != nil {
+				t.Fatalf("DashboardFromRawExtension() error = %v", err)
+			}
+			raw, err := d.RawExtension()
+			if err != nil {
+				t.Fatalf("RawExtension() error = %v", err)
+			}
+			if !testutil.JSONEqual(t, tt.model, raw.Raw) {
+				t.Errorf("round trip got = %s, want %s", raw.Raw, tt.model)
+			}
+		})
+	}
+}
+
+func TestDashboard_Typed(t *testing.T) {
+	model, err := os.ReadFile("./testdata/dashboard.yaml")
+	if err != nil {
+		t.Fatalf("failed to read json model, reason: %v", err)
+	}
+	d := &Dashboard{}
+	if err = json.Unmarshal(model, d); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if d.Title != "KubeDB / Postgres / Summary" || d.SchemaVersion != 27 || d.Version != 2 {
+		t.Errorf("dashboard got title = %v, schemaVersion = %v, version = %v", d.Title, d.SchemaVersion, d.Version)
+	}
+	if _, ok := d.Extra["gnetId"]; !ok {
+		t.Errorf("Extra got = %v, want gnetId", d.Extra)
+	}
+	if got := len(d.Templating.List); got != 3 {
+		t.Fatalf("templating variables got = %v, want 3", got)
+	}
+	if v := d.Templating.List[1]; v.Name != "namespace" || v.Current.Value.String() != "demo" || v.AllValue != `".+"` {
+		t.Errorf("variable got = %+v", v)
+	}
+	p := d.Panels[1]
+	if p.Type != "stat" || p.Datasource.Name != "${datasource}" || !p.Datasource.IsVariable() {
+		t.Errorf("panel got type = %v, datasource = %+v", p.Type, p.Datasource)
+	}
+	if p.GridPos != (GridPos{H: 3, W: 4, X: 0, Y: 1}) {
+		t.Errorf("panel gridPos got = %+v", p.GridPos)
+	}
+	if len(p.Targets) == 0 || p.Targets[0].Expr == "" {
+		t.Errorf("panel targets got = %+v", p.Targets)
+	}
+	if steps := p.FieldConfig.Defaults.Thresholds.Steps; len(steps) != 1 || steps[0].Value != nil {
+		t.Errorf("panel thresholds got = %+v", steps)
+	}
+	if !d.Panels[0].IsRow() {
+		t.Errorf("first panel got type = %v, want row", d.Panels[0].Type)
+	}
+
+	d.Panels[1].Title = "Version"
+	d.Panels[1].GridPos.W = 6
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got map[string]any
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	panel := got["panels"].([]any)[1].(map[string]any)
+	if panel["title"] != "Version" || panel["gridPos"].(map[string]any)["w"] != float64(6) {
+		t.Errorf("modified panel got = %v", panel)
+	}
+	if panel["cacheTimeout"] != nil {
+		t.Errorf("panel cacheTimeout got = %v, want null", panel["cacheTimeout"])
+	}
+	if _, ok := panel["interval"]; !ok {
+		t.Errorf("panel lost null interval")
+	}
+}
+
+func TestDashboard_AllPanels(t *testing.T) {
+	d := &Dashboard{
+		Panels: []Panel{
+			{ID: 1, Type: "row", Collapsed: true, Panels: []Panel{{ID: 2}, {ID: 3}}},
+			{ID: 4, Type: "row"},
+			{ID: 5},
+		},
+	}
+	var got []int
+	for _, p := range d.AllPanels() {
+		got = append(got, p.ID)
+	}
+	if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AllPanels() got = %v, want %v", got, want)
+	}
+}
+
+func TestDataSourceRef_IsBuiltin(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  *DataSourceRef
+		want bool
+	}{
+		{name: "Nil", ref: nil, want: false},
+		{name: "Grafana type", ref: &DataSourceRef{Type: "grafana", UID: "grafana"}, want: true},
+		{name: "Mixed uid", ref: &DataSourceRef{UID: "-- Mixed --"}, want: true},
+		{name: "Dashboard name", ref: &DataSourceRef{Name: "-- Dashboard --"}, want: true},
+		{name: "Prometheus", ref: &DataSourceRef{Type: "prometheus", UID: "P1"}, want: false},
+		{name: "Variable", ref: &DataSourceRef{UID: "${ds}"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ref.IsBuiltin(); got != tt.want {
+				t.Errorf("IsBuiltin() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
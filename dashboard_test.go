@@ -0,0 +1,109 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_GetDashboardByUID(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		respBody     string
+		wantErr      bool
+		wantNotFound bool
+		wantMeta     DashboardMeta
+	}{
+		{
+			name:       "Get Dashboard",
+			statusCode: http.StatusOK,
+			respBody: `{
+  "dashboard": {"uid": "pg-summary", "title": "KubeDB / Postgres / Summary", "version": 3},
+  "meta": {
+    "type": "db", "canSave": true, "canEdit": true, "slug": "kubedb-postgres-summary",
+    "url": "/d/pg-summary/kubedb-postgres-summary", "created": "2024-01-02T03:04:05Z",
+    "updated": "2024-01-03T03:04:05Z", "updatedBy": "admin", "createdBy": "admin", "version": 3,
+    "folderId": 7, "folderUid": "databases", "folderTitle": "Databases", "provisioned": true,
+    "provisionedExternalId": "postgres.json"
+  }
+}`,
+			wantMeta: DashboardMeta{
+				Type:                  "db",
+				Slug:                  "kubedb-postgres-summary",
+				URL:                   "/d/pg-summary/kubedb-postgres-summary",
+				Version:               3,
+				CanSave:               true,
+				CanEdit:               true,
+				Created:               time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Updated:               time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC),
+				CreatedBy:             "admin",
+				UpdatedBy:             "admin",
+				FolderID:              7,
+				FolderUID:             "databases",
+				FolderTitle:           "Databases",
+				Provisioned:           true,
+				ProvisionedExternalID: "postgres.json",
+			},
+		},
+		{
+			name:         "Dashboard not found",
+			statusCode:   http.StatusNotFound,
+			respBody:     `{"message":"Dashboard not found"}`,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:       "Server error",
+			statusCode: http.StatusInternalServerError,
+			respBody:   `{"message":"Internal server error"}`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, reqs := newRecordingServer(t, tt.statusCode, tt.respBody)
+			c, err := NewClient(srv.URL, WithAuth(validAuth))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			got, err := c.GetDashboardByUID(context.TODO(), "pg-summary")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetDashboardByUID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsNotFound(err) != tt.wantNotFound {
+				t.Errorf("IsNotFound(%v) = %v, want %v", err, IsNotFound(err), tt.wantNotFound)
+			}
+			if path := (*reqs)[0].Path; path != "/api/dashboards/uid/pg-summary" {
+				t.Errorf("request path got = %v, want /api/dashboards/uid/pg-summary", path)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Meta != tt.wantMeta {
+				t.Errorf("GetDashboardByUID() meta got = %+v, want %+v", got.Meta, tt.wantMeta)
+			}
+			model, err := got.Dashboard.MarshalJSON()
+			if err != nil || len(model) == 0 {
+				t.Errorf("GetDashboardByUID() dashboard got = %s, err = %v", model, err)
+			}
+		})
+	}
+}
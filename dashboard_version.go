@@ -0,0 +1,163 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DashboardVersion as described in the doc
+// https://grafana.com/docs/grafana/latest/developers/http_api/dashboard_versions/
+type DashboardVersion struct {
+	ID            int       `json:"id"`
+	DashboardID   int       `json:"dashboardId"`
+	UID           string    `json:"uid,omitempty"`
+	ParentVersion int       `json:"parentVersion"`
+	RestoredFrom  int       `json:"restoredFrom"`
+	Version       int       `json:"version"`
+	Created       time.Time `json:"created"`
+	CreatedBy     string    `json:"createdBy,omitempty"`
+	Message       string    `json:"message,omitempty"`
+	// Data is the dashboard model of the version. It is only set by GetDashboardVersion.
+	Data *runtime.RawExtension `json:"data,omitempty"`
+}
+
+// DiffType selects the format of a dashboard diff.
+type DiffType string
+
+const (
+	DiffTypeJSON  DiffType = "json"
+	DiffTypeBasic DiffType = "basic"
+)
+
+// ListDashboardVersions returns the saved versions of the dashboard with the given uid, newest first.
+// limit and start page through the versions; zero values use the server defaults.
+// It reflects GET /api/dashboards/uid/:uid/versions API call.
+func (c *Client) ListDashboardVersions(ctx context.Context, uid string, limit, start int) ([]DashboardVersion, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/dashboards/uid", uid, "versions")
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	if start > 0 {
+		q.Set("start", strconv.Itoa(start))
+	}
+	u.RawQuery = q.Encode()
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	// Grafana 11 wraps the list into an object with a continue token.
+	var versions []DashboardVersion
+	if body := bytes.TrimSpace(resp.body); len(body) > 0 && body[0] == '{' {
+		var list struct {
+			Versions []DashboardVersion `json:"versions"`
+		}
+		err = json.Unmarshal(body, &list)
+		versions = list.Versions
+	} else {
+		err = json.Unmarshal(body, &versions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response of %s %s, reason: %w", resp.method, resp.url, err)
+	}
+	return versions, nil
+}
+
+// GetDashboardVersion returns a single version of the dashboard with the given uid including its model.
+// versionID is the DashboardVersion.ID returned by ListDashboardVersions.
+// It reflects GET /api/dashboards/uid/:uid/versions/:id API call.
+func (c *Client) GetDashboardVersion(ctx context.Context, uid string, versionID int) (*DashboardVersion, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/dashboards/uid", uid, "versions", strconv.Itoa(versionID))
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	version := &DashboardVersion{}
+	if err = decodeResponse(resp, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// RestoreDashboardVersion saves the given version of the dashboard with the given uid as its newest version.
+// It reflects POST /api/dashboards/uid/:uid/restore API call.
+func (c *Client) RestoreDashboardVersion(ctx context.Context, uid string, version int) (*GrafanaResponse, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/dashboards/uid", uid, "restore")
+	resp, err := c.do(ctx, http.MethodPost, u.String(), map[string]int{"version": version})
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
+
+type diffTarget struct {
+	DashboardID int `json:"dashboardId"`
+	Version     int `json:"version"`
+}
+
+type diffRequest struct {
+	Base     diffTarget `json:"base"`
+	New      diffTarget `json:"new"`
+	DiffType DiffType   `json:"diffType"`
+}
+
+// CalculateDashboardDiff returns the diff between two versions of the dashboard with the given uid
+// as rendered by Grafana. Both diff types are returned as HTML fragments.
+// It reflects POST /api/dashboards/calculate-diff API call.
+func (c *Client) CalculateDashboardDiff(ctx context.Context, uid string, baseVersion, newVersion int, diffType DiffType) ([]byte, error) {
+	db, err := c.GetDashboardByUID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	model, err := db.Model()
+	if err != nil {
+		return nil, err
+	}
+
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/dashboards/calculate-diff")
+	resp, err := c.do(ctx, http.MethodPost, u.String(), diffRequest{
+		Base:     diffTarget{DashboardID: model.ID, Version: baseVersion},
+		New:      diffTarget{DashboardID: model.ID, Version: newVersion},
+		DiffType: diffType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp.body, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListDashboardVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		respBody string
+	}{
+		{
+			name:     "Versions as array",
+			respBody: `[{"id":2,"dashboardId":1,"uid":"pg","parentVersion":1,"version":2,"created":"2024-01-02T03:04:05Z","createdBy":"admin"},{"id":1,"dashboardId":1,"uid":"pg","version":1,"created":"2024-01-01T03:04:05Z","createdBy":"admin"}]`,
+		},
+		{
+			name:     "Versions with continue token",
+			respBody: `{"continueToken":"","versions":[{"id":2,"dashboardId":1,"uid":"pg","parentVersion":1,"version":2,"created":"2024-01-02T03:04:05Z","createdBy":"admin"},{"id":1,"dashboardId":1,"uid":"pg","version":1,"created":"2024-01-01T03:04:05Z","createdBy":"admin"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotQuery string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/dashboards/uid/pg/versions" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				gotQuery = r.URL.RawQuery
+				_, _ = w.Write([]byte(tt.respBody))
+			}))
+			defer srv.Close()
+			c, err := NewClient(srv.URL, WithAuth(validAuth))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			got, err := c.ListDashboardVersions(context.TODO(), "pg", 10, 0)
+			if err != nil {
+				t.Fatalf("ListDashboardVersions() error = %v", err)
+			}
+			if len(got) != 2 || got[0].Version != 2 || got[0].ParentVersion != 1 || got[1].Version != 1 {
+				t.Errorf("ListDashboardVersions() got = %+v", got)
+			}
+			if gotQuery != "limit=10" {
+				t.Errorf("query got = %v, want limit=10", gotQuery)
+			}
+		})
+	}
+}
+
+func TestClient_DashboardVersions(t *testing.T) {
+	var gotDiff diffRequest
+	var gotRestore map[string]int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/dashboards/uid/pg", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"dashboard":{"id":42,"uid":"pg","version":3},"meta":{"version":3}}`))
+	})
+	mux.HandleFunc("GET /api/dashboards/uid/pg/versions/2", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":2,"dashboardId":42,"version":2,"data":{"id":42,"uid":"pg","title":"Postgres","version":2}}`))
+	})
+	mux.HandleFunc("POST /api/dashboards/uid/pg/restore", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotRestore)
+		_, _ = w.Write([]byte(`{"id":42,"uid":"pg","status":"success","version":4}`))
+	})
+	mux.HandleFunc("POST /api/dashboards/calculate-diff", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotDiff)
+		_, _ = w.Write([]byte(`<div class="diff-group">title changed</div>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	version, err := c.GetDashboardVersion(context.TODO(), "pg", 2)
+	if err != nil {
+		t.Fatalf("GetDashboardVersion() error = %v", err)
+	}
+	model, err := DashboardFromRawExtension(version.Data)
+	if err != nil || model.Title != "Postgres" || model.Version != 2 {
+		t.Errorf("GetDashboardVersion() data got = %+v, err = %v", model, err)
+	}
+
+	restored, err := c.RestoreDashboardVersion(context.TODO(), "pg", 2)
+	if err != nil {
+		t.Fatalf("RestoreDashboardVersion() error = %v", err)
+	}
+	if gotRestore["version"] != 2 || restored.Version == nil || *restored.Version != 4 {
+		t.Errorf("RestoreDashboardVersion() request = %v, response = %+v", gotRestore, restored)
+	}
+
+	diff, err := c.CalculateDashboardDiff(context.TODO(), "pg", 2, 3, DiffTypeBasic)
+	if err != nil {
+		t.Fatalf("CalculateDashboardDiff() error = %v", err)
+	}
+	want := diffRequest{
+		Base:     diffTarget{DashboardID: 42, Version: 2},
+		New:      diffTarget{DashboardID: 42, Version: 3},
+		DiffType: DiffTypeBasic,
+	}
+	if gotDiff != want {
+		t.Errorf("CalculateDashboardDiff() request got = %+v, want %+v", gotDiff, want)
+	}
+	if string(diff) != `<div class="diff-group">title changed</div>` {
+		t.Errorf("CalculateDashboardDiff() got = %s", diff)
+	}
+
+	if _, err = c.GetDashboardVersion(context.TODO(), "missing", 1); !IsNotFound(err) {
+		t.Errorf("GetDashboardVersion() error = %v, want not found", err)
+	}
+}
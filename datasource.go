@@ -0,0 +1,130 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+type datasourceIDResponse struct {
+	ID int `json:"id"`
+}
+
+// datasourceURL returns the URL of api/datasources/<kind>/<key>. The key is escaped as a single
+// path segment, so datasource names may contain slashes.
+func (c *Client) datasourceURL(kind, key string) string {
+	u, _ := url.Parse(c.baseURL)
+	u.RawPath = path.Join(u.EscapedPath(), "api/datasources", kind) + "/" + url.PathEscape(key)
+	u.Path = path.Join(u.Path, "api/datasources", kind) + "/" + key
+	return u.String()
+}
+
+// ListDatasources returns all datasources of the current organization.
+// It reflects GET /api/datasources API call.
+func (c *Client) ListDatasources(ctx context.Context) ([]Datasource, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/datasources")
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var list []Datasource
+	if err = decodeResponse(resp, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetDatasourceByUID returns the datasource with the given uid.
+// It reflects GET /api/datasources/uid/:uid API call.
+func (c *Client) GetDatasourceByUID(ctx context.Context, uid string) (*Datasource, error) {
+	return c.getDatasource(ctx, c.datasourceURL("uid", uid))
+}
+
+// GetDatasourceByName returns the datasource with the given name.
+// It reflects GET /api/datasources/name/:name API call.
+func (c *Client) GetDatasourceByName(ctx context.Context, name string) (*Datasource, error) {
+	return c.getDatasource(ctx, c.datasourceURL("name", name))
+}
+
+func (c *Client) getDatasource(ctx context.Context, u string) (*Datasource, error) {
+	resp, err := c.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	ds := &Datasource{}
+	if err = decodeResponse(resp, ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// GetDatasourceIDByName returns the numeric id of the datasource with the given name.
+// It reflects GET /api/datasources/id/:name API call.
+func (c *Client) GetDatasourceIDByName(ctx context.Context, name string) (int, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.datasourceURL("id", name), nil)
+	if err != nil {
+		return 0, err
+	}
+	var out datasourceIDResponse
+	if err = decodeResponse(resp, &out); err != nil {
+		return 0, err
+	}
+	return out.ID, nil
+}
+
+// UpdateDatasourceByUID updates the datasource with the uid of ds. Secure fields that are
+// not set in ds.SecureJSONData keep their values.
+// It reflects PUT /api/datasources/uid/:uid API call.
+func (c *Client) UpdateDatasourceByUID(ctx context.Context, ds Datasource) (*GrafanaResponse, error) {
+	if ds.UID == "" {
+		return nil, errors.New("failed to update datasource, reason: missing uid")
+	}
+	return c.updateDatasource(ctx, ds.UID, ds)
+}
+
+func (c *Client) updateDatasource(ctx context.Context, uid string, ds Datasource) (*GrafanaResponse, error) {
+	resp, err := c.do(ctx, http.MethodPut, c.datasourceURL("uid", uid), ds)
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
+
+// DeleteDatasourceByUID deletes the datasource with the given uid.
+// It reflects DELETE /api/datasources/uid/:uid API call.
+func (c *Client) DeleteDatasourceByUID(ctx context.Context, uid string) (*GrafanaResponse, error) {
+	return c.deleteDatasource(ctx, c.datasourceURL("uid", uid))
+}
+
+// DeleteDatasourceByName deletes the datasource with the given name.
+// It reflects DELETE /api/datasources/name/:name API call.
+func (c *Client) DeleteDatasourceByName(ctx context.Context, name string) (*GrafanaResponse, error) {
+	return c.deleteDatasource(ctx, c.datasourceURL("name", name))
+}
+
+func (c *Client) deleteDatasource(ctx context.Context, u string) (*GrafanaResponse, error) {
+	resp, err := c.do(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package datasource provides typed jsonData and secureJsonData of common Grafana datasource
+// plugins, so that they need not be built as maps.
+//
+//	ds := sdk.Datasource{Name: "Prometheus", Type: datasource.TypePrometheus, Access: "proxy", URL: "http://prometheus:9090"}
+//	err := datasource.Apply(&ds, datasource.Prometheus{HTTPMethod: http.MethodPost, TimeInterval: "30s"}, nil)
+//
+// Plugins without typed options are supported through Generic.
+package datasource
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+// JSONData is the typed jsonData of a datasource plugin.
+type JSONData interface {
+	// Validate returns an error if the settings would be rejected by the plugin or can never work.
+	Validate() error
+}
+
+// Generic is the jsonData of plugins without typed options.
+type Generic map[string]any
+
+// Validate implements JSONData; Generic jsonData is never validated.
+func (Generic) Validate() error {
+	return nil
+}
+
+// SecureJSONData holds the secrets of a datasource. Grafana stores them encrypted and never returns
+// them. Plugins use the subset that applies to them.
+type SecureJSONData struct {
+	// Password is the password of database plugins, e.g. PostgreSQL and MySQL.
+	Password          string `json:"password,omitempty"`
+	BasicAuthPassword string `json:"basicAuthPassword,omitempty"`
+	TLSCACert         string `json:"tlsCACert,omitempty"`
+	TLSClientCert     string `json:"tlsClientCert,omitempty"`
+	TLSClientKey      string `json:"tlsClientKey,omitempty"`
+	// HTTPHeaders are custom headers sent by HTTP based plugins, keyed by name. Grafana stores the
+	// names as httpHeaderName<n> in jsonData and the values as httpHeaderValue<n> in secureJsonData.
+	HTTPHeaders map[string]string `json:"-"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]func() JSONData{
+		TypePrometheus:     func() JSONData { return &Prometheus{} },
+		TypeLoki:           func() JSONData { return &Loki{} },
+		TypePostgres:       func() JSONData { return &Postgres{} },
+		TypePostgresLegacy: func() JSONData { return &Postgres{} },
+		TypeMySQL:          func() JSONData { return &MySQL{} },
+		TypeElasticsearch:  func() JSONData { return &Elasticsearch{} },
+		TypeTempo:          func() JSONData { return &Tempo{} },
+	}
+)
+
+// Register registers the typed jsonData of the plugin with the given type, replacing any
+// previous registration. newJSONData must return a pointer, so that jsonData can be decoded into it.
+func Register(pluginType string, newJSONData func() JSONData) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[pluginType] = newJSONData
+}
+
+// Types returns the sorted plugin types with typed jsonData.
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New returns empty jsonData of the plugin with the given type, or Generic if the plugin is not
+// registered.
+func New(pluginType string) JSONData {
+	registryMu.RLock()
+	fn, ok := registry[pluginType]
+	registryMu.RUnlock()
+	if !ok {
+		return Generic{}
+	}
+	return fn()
+}
+
+// Decode returns the jsonData of ds typed by the plugin type of ds. Keys that are not part of the
+// typed jsonData are not returned, use Generic to get all of them. Apply keeps them on ds, so the
+// result can be modified and applied again.
+func Decode(ds *sdk.Datasource) (JSONData, error) {
+	out := New(ds.Type)
+	if ds.JSONData == nil {
+		return out, nil
+	}
+	data, err := json.Marshal(ds.JSONData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode jsonData of datasource %q, reason: %w", ds.Name, err)
+	}
+	if g, ok := out.(Generic); ok {
+		err = json.Unmarshal(data, &g)
+		out = g
+	} else {
+		err = json.Unmarshal(data, out)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode jsonData of datasource %q, reason: %w", ds.Name, err)
+	}
+	return out, nil
+}
+
+// Apply validates jsonData and merges it into the jsonData of ds, and sets secure, if not nil, as
+// its secureJsonData. jsonData must be of the type registered for ds.Type, or Generic. The fields of
+// typed jsonData replace the existing values, including the ones it leaves empty, while other keys
+// of ds are kept. The header names of ds are only replaced if secure has HTTPHeaders.
+func Apply(ds *sdk.Datasource, jsonData JSONData, secure *SecureJSONData) error {
+	if jsonData == nil {
+		jsonData = Generic{}
+	}
+	if _, generic := jsonData.(Generic); !generic {
+		if want, got := baseType(New(ds.Type)), baseType(jsonData); want != got {
+			return fmt.Errorf("failed to apply options to datasource %q, reason: %v is not the jsonData of plugin %q", ds.Name, got, ds.Type)
+		}
+	}
+	if err := jsonData.Validate(); err != nil {
+		return fmt.Errorf("failed to apply options to datasource %q, reason: %w", ds.Name, err)
+	}
+
+	values, err := toMap(jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to apply options to datasource %q, reason: %w", ds.Name, err)
+	}
+	// toMap copies the jsonData of ds, so that it is not modified on errors
+	data, err := toMap(ds.JSONData)
+	if err != nil {
+		return fmt.Errorf("failed to apply options to datasource %q, reason: %w", ds.Name, err)
+	}
+	if _, generic := jsonData.(Generic); !generic {
+		for _, name := range fieldNames(baseType(jsonData)) {
+			delete(data, name)
+		}
+	}
+	maps.Copy(data, values)
+	if secure == nil {
+		ds.JSONData = data
+		return nil
+	}
+
+	secureData, err := toMap(secure)
+	if err != nil {
+		return fmt.Errorf("failed to apply options to datasource %q, reason: %w", ds.Name, err)
+	}
+	if secure.HTTPHeaders != nil {
+		for key := range data {
+			if strings.HasPrefix(key, httpHeaderNamePrefix) {
+				delete(data, key)
+			}
+		}
+	}
+	names := make([]string, 0, len(secure.HTTPHeaders))
+	for name := range secure.HTTPHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		data[fmt.Sprintf("%s%d", httpHeaderNamePrefix, i+1)] = name
+		secureData[fmt.Sprintf("httpHeaderValue%d", i+1)] = secure.HTTPHeaders[name]
+	}
+	ds.JSONData = data
+	ds.SecureJSONData = secureData
+	return nil
+}
+
+const httpHeaderNamePrefix = "httpHeaderName"
+
+// fieldNames returns the JSON names of the fields of the struct type t, including the fields of
+// embedded structs.
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case name == "-":
+		case f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct:
+			names = append(names, fieldNames(f.Type)...)
+		case !f.IsExported():
+		case name == "":
+			names = append(names, f.Name)
+		default:
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func baseType(v any) reflect.Type {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func toMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err = json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = map[string]any{}
+	}
+	return out, nil
+}
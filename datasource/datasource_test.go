@@ -0,0 +1,202 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/internal/testutil"
+)
+
+func TestApply(t *testing.T) {
+	ds := &sdk.Datasource{Name: "Prometheus", Type: TypePrometheus}
+	err := Apply(ds, Prometheus{
+		HTTPOptions:  HTTPOptions{Timeout: 60},
+		HTTPMethod:   "POST",
+		TimeInterval: "30s",
+		ExemplarTraceIDDestinations: []ExemplarTraceIDDestination{
+			{Name: "trace_id", DatasourceUID: "tempo"},
+		},
+	}, &SecureJSONData{
+		BasicAuthPassword: "secret",
+		HTTPHeaders:       map[string]string{"X-Scope-OrgID": "tenant", "Authorization": "Bearer token"},
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	testutil.AssertJSON(t, ds.JSONData, `{
+  "timeout": 60, "httpMethod": "POST", "timeInterval": "30s",
+  "exemplarTraceIdDestinations": [{"name": "trace_id", "datasourceUid": "tempo"}],
+  "httpHeaderName1": "Authorization", "httpHeaderName2": "X-Scope-OrgID"
+}`)
+	testutil.AssertJSON(t, ds.SecureJSONData, `{"basicAuthPassword": "secret", "httpHeaderValue1": "Bearer token", "httpHeaderValue2": "tenant"}`)
+
+	ds = &sdk.Datasource{Name: "Postgres", Type: TypePostgresLegacy, SecureJSONData: map[string]any{"password": "kept"}}
+	if err = Apply(ds, &Postgres{SSLMode: "disable", PostgresVersion: 1500, TimescaleDB: true}, nil); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	testutil.AssertJSON(t, ds.JSONData, `{"sslmode": "disable", "postgresVersion": 1500, "timescaledb": true}`)
+	testutil.AssertJSON(t, ds.SecureJSONData, `{"password": "kept"}`)
+
+	ds = &sdk.Datasource{Name: "Custom", Type: "example-datasource"}
+	if err = Apply(ds, Generic{"path": "/api"}, &SecureJSONData{Password: "secret"}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	testutil.AssertJSON(t, ds.JSONData, `{"path": "/api"}`)
+	testutil.AssertJSON(t, ds.SecureJSONData, `{"password": "secret"}`)
+}
+
+func TestApply_RoundTrip(t *testing.T) {
+	ds := &sdk.Datasource{Name: "Prometheus", Type: TypePrometheus, JSONData: map[string]any{
+		"httpMethod": "GET", "timeout": 30, "sigV4Auth": true,
+		"httpHeaderName1": "X-Scope-OrgID",
+	}}
+	jsonData, err := Decode(ds)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	prom := jsonData.(*Prometheus)
+	prom.HTTPMethod = "POST"
+	prom.Timeout = 0
+	if err = Apply(ds, prom, nil); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	testutil.AssertJSON(t, ds.JSONData, `{"httpMethod": "POST", "sigV4Auth": true, "httpHeaderName1": "X-Scope-OrgID"}`)
+
+	if err = Apply(ds, prom, &SecureJSONData{HTTPHeaders: map[string]string{"Authorization": "Bearer token"}}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	testutil.AssertJSON(t, ds.JSONData, `{"httpMethod": "POST", "sigV4Auth": true, "httpHeaderName1": "Authorization"}`)
+	testutil.AssertJSON(t, ds.SecureJSONData, `{"httpHeaderValue1": "Bearer token"}`)
+
+	if err = Apply(ds, Generic{"sigV4Region": "us-east-1"}, nil); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	testutil.AssertJSON(t, ds.JSONData, `{"httpMethod": "POST", "sigV4Auth": true, "sigV4Region": "us-east-1", "httpHeaderName1": "Authorization"}`)
+}
+
+func TestApply_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      string
+		jsonData JSONData
+		want     string
+	}{
+		{name: "Wrong plugin", typ: TypeLoki, jsonData: Prometheus{}, want: "is not the jsonData of plugin"},
+		{name: "Typed options of unknown plugin", typ: "example-datasource", jsonData: Loki{}, want: "is not the jsonData of plugin"},
+		{name: "Prometheus method", typ: TypePrometheus, jsonData: Prometheus{HTTPMethod: "PUT"}, want: "httpMethod"},
+		{name: "Prometheus scrape interval", typ: TypePrometheus, jsonData: Prometheus{TimeInterval: "15"}, want: "timeInterval"},
+		{name: "Prometheus exemplar", typ: TypePrometheus, jsonData: Prometheus{
+			ExemplarTraceIDDestinations: []ExemplarTraceIDDestination{{Name: "trace_id", DatasourceUID: "tempo", URL: "http://jaeger"}},
+		}, want: "either a datasource or a url"},
+		{name: "Loki max lines", typ: TypeLoki, jsonData: Loki{MaxLines: -1}, want: "maxLines"},
+		{name: "Loki derived field regex", typ: TypeLoki, jsonData: Loki{
+			DerivedFields: []DerivedField{{Name: "TraceID", MatcherRegex: "traceID=(\\w+"}},
+		}, want: "invalid matcherRegex"},
+		{name: "Postgres sslmode", typ: TypePostgres, jsonData: Postgres{SSLMode: "prefer"}, want: "sslmode"},
+		{name: "Postgres version", typ: TypePostgres, jsonData: Postgres{PostgresVersion: 15}, want: "postgresVersion"},
+		{name: "MySQL pool", typ: TypeMySQL, jsonData: MySQL{SQLOptions: SQLOptions{MaxOpenConns: 5, MaxIdleConns: 10}}, want: "maxIdleConns"},
+		{name: "Elasticsearch time field", typ: TypeElasticsearch, jsonData: Elasticsearch{Index: "logs-*"}, want: "timeField"},
+		{name: "Tempo traces to logs", typ: TypeTempo, jsonData: Tempo{TracesToLogsV2: &TracesToLogs{DatasourceUID: "loki", SpanStartTimeShift: "1 hour"}}, want: "spanStartTimeShift"},
+		{name: "Tempo service map", typ: TypeTempo, jsonData: Tempo{ServiceMap: &DatasourceLink{}}, want: "serviceMap"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &sdk.Datasource{Name: "ds", Type: tt.typ}
+			err := Apply(ds, tt.jsonData, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Apply() error = %v, want it to contain %q", err, tt.want)
+			}
+			if ds.JSONData != nil {
+				t.Errorf("Apply() set jsonData = %v", ds.JSONData)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		ds   sdk.Datasource
+		want JSONData
+	}{
+		{
+			name: "Loki",
+			ds: sdk.Datasource{Type: TypeLoki, JSONData: map[string]any{
+				"maxLines": 1000, "timeout": 30, "unknown": true,
+				"derivedFields": []any{map[string]any{"name": "TraceID", "matcherRegex": "trace_id", "matcherType": "label", "datasourceUid": "tempo"}},
+			}},
+			want: &Loki{
+				HTTPOptions:   HTTPOptions{Timeout: 30},
+				MaxLines:      1000,
+				DerivedFields: []DerivedField{{Name: "TraceID", MatcherRegex: "trace_id", MatcherType: "label", DatasourceUID: "tempo"}},
+			},
+		},
+		{
+			name: "Without jsonData",
+			ds:   sdk.Datasource{Type: TypeElasticsearch},
+			want: &Elasticsearch{},
+		},
+		{
+			name: "Unknown plugin",
+			ds:   sdk.Datasource{Type: "example-datasource", JSONData: map[string]any{"path": "/api"}},
+			want: Generic{"path": "/api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(&tt.ds)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+type exampleJSONData struct {
+	Path string `json:"path"`
+}
+
+func (e exampleJSONData) Validate() error {
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("example-datasource", func() JSONData { return &exampleJSONData{} })
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "example-datasource")
+		registryMu.Unlock()
+	})
+
+	got, err := Decode(&sdk.Datasource{Type: "example-datasource", JSONData: map[string]any{"path": "/api"}})
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if want := (&exampleJSONData{Path: "/api"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() got = %#v, want %#v", got, want)
+	}
+	if !strings.Contains(strings.Join(Types(), ","), "example-datasource") {
+		t.Errorf("Types() got = %v", Types())
+	}
+}
@@ -0,0 +1,405 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+)
+
+// Plugin types, as set in Datasource.Type.
+const (
+	TypePrometheus    = "prometheus"
+	TypeLoki          = "loki"
+	TypePostgres      = "grafana-postgresql-datasource"
+	TypeMySQL         = "mysql"
+	TypeElasticsearch = "elasticsearch"
+	TypeTempo         = "tempo"
+	// TypePostgresLegacy is the type of PostgreSQL datasources created before Grafana 10.
+	TypePostgresLegacy = "postgres"
+)
+
+// duration matches Grafana durations like 15s, 1m or -1h.
+var duration = regexp.MustCompile(`^-?\d+(ms|s|m|h|d|w|M|y)$`)
+
+func checkDuration(field, value string) error {
+	if value != "" && !duration.MatchString(value) {
+		return fmt.Errorf("%s must be a duration like 15s or 1m, got %q", field, value)
+	}
+	return nil
+}
+
+func checkOneOf(field, value string, allowed ...string) error {
+	if value != "" && !slices.Contains(allowed, value) {
+		return fmt.Errorf("%s must be one of %q, got %q", field, allowed, value)
+	}
+	return nil
+}
+
+// HTTPOptions are the jsonData settings shared by HTTP based plugins.
+type HTTPOptions struct {
+	TLSAuth           bool   `json:"tlsAuth,omitempty"`
+	TLSAuthWithCACert bool   `json:"tlsAuthWithCACert,omitempty"`
+	TLSSkipVerify     bool   `json:"tlsSkipVerify,omitempty"`
+	ServerName        string `json:"serverName,omitempty"`
+	// Timeout is the HTTP request timeout in seconds.
+	Timeout       int      `json:"timeout,omitempty"`
+	OAuthPassThru bool     `json:"oauthPassThru,omitempty"`
+	KeepCookies   []string `json:"keepCookies,omitempty"`
+}
+
+func (o HTTPOptions) validate() error {
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %d", o.Timeout)
+	}
+	return nil
+}
+
+// SQLOptions are the connection pool settings shared by SQL plugins.
+type SQLOptions struct {
+	MaxOpenConns     int  `json:"maxOpenConns,omitempty"`
+	MaxIdleConns     int  `json:"maxIdleConns,omitempty"`
+	MaxIdleConnsAuto bool `json:"maxIdleConnsAuto,omitempty"`
+	// ConnMaxLifetime is the maximum lifetime of a connection in seconds.
+	ConnMaxLifetime int `json:"connMaxLifetime,omitempty"`
+}
+
+func (o SQLOptions) validate() error {
+	if o.MaxOpenConns < 0 || o.MaxIdleConns < 0 || o.ConnMaxLifetime < 0 {
+		return errors.New("maxOpenConns, maxIdleConns and connMaxLifetime must not be negative")
+	}
+	if o.MaxOpenConns > 0 && o.MaxIdleConns > o.MaxOpenConns {
+		return fmt.Errorf("maxIdleConns must not exceed maxOpenConns %d, got %d", o.MaxOpenConns, o.MaxIdleConns)
+	}
+	return nil
+}
+
+// Prometheus is the jsonData of the Prometheus plugin.
+type Prometheus struct {
+	HTTPOptions
+	HTTPMethod string `json:"httpMethod,omitempty"`
+	// TimeInterval is the scrape interval, used as the minimum step and for $__rate_interval.
+	TimeInterval          string `json:"timeInterval,omitempty"`
+	QueryTimeout          string `json:"queryTimeout,omitempty"`
+	PrometheusType        string `json:"prometheusType,omitempty"`
+	PrometheusVersion     string `json:"prometheusVersion,omitempty"`
+	CacheLevel            string `json:"cacheLevel,omitempty"`
+	IncrementalQuerying   bool   `json:"incrementalQuerying,omitempty"`
+	DisableMetricsLookup  bool   `json:"disableMetricsLookup,omitempty"`
+	CustomQueryParameters string `json:"customQueryParameters,omitempty"`
+	ManageAlerts          *bool  `json:"manageAlerts,omitempty"`
+	// ExemplarTraceIDDestinations link exemplars to traces.
+	ExemplarTraceIDDestinations []ExemplarTraceIDDestination `json:"exemplarTraceIdDestinations,omitempty"`
+}
+
+// ExemplarTraceIDDestination links the trace id label of exemplars either to a tracing
+// datasource or to an external URL.
+type ExemplarTraceIDDestination struct {
+	// Name is the exemplar label holding the trace id.
+	Name            string `json:"name"`
+	DatasourceUID   string `json:"datasourceUid,omitempty"`
+	URL             string `json:"url,omitempty"`
+	URLDisplayLabel string `json:"urlDisplayLabel,omitempty"`
+}
+
+// Validate implements JSONData.
+func (p Prometheus) Validate() error {
+	if err := p.HTTPOptions.validate(); err != nil {
+		return err
+	}
+	if err := checkOneOf("httpMethod", p.HTTPMethod, http.MethodGet, http.MethodPost); err != nil {
+		return err
+	}
+	if err := checkDuration("timeInterval", p.TimeInterval); err != nil {
+		return err
+	}
+	if err := checkDuration("queryTimeout", p.QueryTimeout); err != nil {
+		return err
+	}
+	if err := checkOneOf("prometheusType", p.PrometheusType, "Prometheus", "Cortex", "Mimir", "Thanos"); err != nil {
+		return err
+	}
+	if err := checkOneOf("cacheLevel", p.CacheLevel, "Low", "Medium", "High", "None"); err != nil {
+		return err
+	}
+	for i, d := range p.ExemplarTraceIDDestinations {
+		if d.Name == "" {
+			return fmt.Errorf("exemplarTraceIdDestinations[%d] has no name", i)
+		}
+		if (d.DatasourceUID == "") == (d.URL == "") {
+			return fmt.Errorf("exemplarTraceIdDestinations[%d] must link to either a datasource or a url", i)
+		}
+	}
+	return nil
+}
+
+// Loki is the jsonData of the Loki plugin.
+type Loki struct {
+	HTTPOptions
+	// MaxLines limits the number of log lines returned by a query.
+	MaxLines      int            `json:"maxLines,omitempty"`
+	DerivedFields []DerivedField `json:"derivedFields,omitempty"`
+}
+
+// DerivedField extracts a value from log lines or labels, usually a trace id, and links it to a
+// datasource or an URL.
+type DerivedField struct {
+	Name string `json:"name"`
+	// MatcherRegex is the regex with one capture group for the value, or the label name if
+	// MatcherType is "label".
+	MatcherRegex    string `json:"matcherRegex"`
+	MatcherType     string `json:"matcherType,omitempty"`
+	URL             string `json:"url,omitempty"`
+	URLDisplayLabel string `json:"urlDisplayLabel,omitempty"`
+	DatasourceUID   string `json:"datasourceUid,omitempty"`
+}
+
+// Validate implements JSONData.
+func (l Loki) Validate() error {
+	if err := l.HTTPOptions.validate(); err != nil {
+		return err
+	}
+	if l.MaxLines < 0 {
+		return fmt.Errorf("maxLines must not be negative, got %d", l.MaxLines)
+	}
+	for i, f := range l.DerivedFields {
+		if f.Name == "" || f.MatcherRegex == "" {
+			return fmt.Errorf("derivedFields[%d] must have a name and a matcherRegex", i)
+		}
+		if err := checkOneOf(fmt.Sprintf("derivedFields[%d].matcherType", i), f.MatcherType, "regex", "label"); err != nil {
+			return err
+		}
+		if f.MatcherType != "label" {
+			if _, err := regexp.Compile(f.MatcherRegex); err != nil {
+				return fmt.Errorf("derivedFields[%d] has an invalid matcherRegex: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Postgres is the jsonData of the PostgreSQL plugin.
+type Postgres struct {
+	SQLOptions
+	Database string `json:"database,omitempty"`
+	SSLMode  string `json:"sslmode,omitempty"`
+	// TLSConfigurationMethod tells whether the TLS certificates are given as "file-path" or as
+	// "file-content" in SecureJSONData.
+	TLSConfigurationMethod string `json:"tlsConfigurationMethod,omitempty"`
+	SSLRootCertFile        string `json:"sslRootCertFile,omitempty"`
+	SSLCertFile            string `json:"sslCertFile,omitempty"`
+	SSLKeyFile             string `json:"sslKeyFile,omitempty"`
+	// PostgresVersion is the server version as major*100+minor before 10, e.g. 906, and as
+	// major*100 since, e.g. 1500.
+	PostgresVersion int    `json:"postgresVersion,omitempty"`
+	TimescaleDB     bool   `json:"timescaledb,omitempty"`
+	TimeInterval    string `json:"timeInterval,omitempty"`
+}
+
+// Validate implements JSONData.
+func (p Postgres) Validate() error {
+	if err := p.SQLOptions.validate(); err != nil {
+		return err
+	}
+	if err := checkOneOf("sslmode", p.SSLMode, "disable", "require", "verify-ca", "verify-full"); err != nil {
+		return err
+	}
+	if err := checkOneOf("tlsConfigurationMethod", p.TLSConfigurationMethod, "file-path", "file-content"); err != nil {
+		return err
+	}
+	if p.PostgresVersion != 0 && p.PostgresVersion < 900 {
+		return fmt.Errorf("postgresVersion must be like 906 or 1500, got %d", p.PostgresVersion)
+	}
+	return checkDuration("timeInterval", p.TimeInterval)
+}
+
+// MySQL is the jsonData of the MySQL plugin.
+type MySQL struct {
+	SQLOptions
+	Database                string `json:"database,omitempty"`
+	TLSAuth                 bool   `json:"tlsAuth,omitempty"`
+	TLSAuthWithCACert       bool   `json:"tlsAuthWithCACert,omitempty"`
+	TLSSkipVerify           bool   `json:"tlsSkipVerify,omitempty"`
+	AllowCleartextPasswords bool   `json:"allowCleartextPasswords,omitempty"`
+	// Timezone is the session time zone, e.g. "+00:00" or "Europe/Berlin".
+	Timezone     string `json:"timezone,omitempty"`
+	TimeInterval string `json:"timeInterval,omitempty"`
+}
+
+// Validate implements JSONData.
+func (m MySQL) Validate() error {
+	if err := m.SQLOptions.validate(); err != nil {
+		return err
+	}
+	return checkDuration("timeInterval", m.TimeInterval)
+}
+
+// Elasticsearch is the jsonData of the Elasticsearch plugin.
+type Elasticsearch struct {
+	HTTPOptions
+	// Index is the index name or pattern, e.g. "[logs-]YYYY.MM.DD" if Interval is set.
+	Index string `json:"index,omitempty"`
+	// TimeField is the name of the time field; it is required.
+	TimeField                  string                  `json:"timeField"`
+	Interval                   string                  `json:"interval,omitempty"`
+	MaxConcurrentShardRequests int                     `json:"maxConcurrentShardRequests,omitempty"`
+	LogMessageField            string                  `json:"logMessageField,omitempty"`
+	LogLevelField              string                  `json:"logLevelField,omitempty"`
+	IncludeFrozen              bool                    `json:"includeFrozen,omitempty"`
+	TimeInterval               string                  `json:"timeInterval,omitempty"`
+	DataLinks                  []ElasticsearchDataLink `json:"dataLinks,omitempty"`
+}
+
+// ElasticsearchDataLink links the value of a field to a datasource or an URL.
+type ElasticsearchDataLink struct {
+	Field           string `json:"field"`
+	URL             string `json:"url,omitempty"`
+	URLDisplayLabel string `json:"urlDisplayLabel,omitempty"`
+	DatasourceUID   string `json:"datasourceUid,omitempty"`
+}
+
+// Validate implements JSONData.
+func (e Elasticsearch) Validate() error {
+	if err := e.HTTPOptions.validate(); err != nil {
+		return err
+	}
+	if e.TimeField == "" {
+		return errors.New("timeField is required")
+	}
+	if err := checkOneOf("interval", e.Interval, "Hourly", "Daily", "Weekly", "Monthly", "Yearly"); err != nil {
+		return err
+	}
+	if e.MaxConcurrentShardRequests < 0 {
+		return fmt.Errorf("maxConcurrentShardRequests must not be negative, got %d", e.MaxConcurrentShardRequests)
+	}
+	if err := checkDuration("timeInterval", e.TimeInterval); err != nil {
+		return err
+	}
+	for i, l := range e.DataLinks {
+		if l.Field == "" {
+			return fmt.Errorf("dataLinks[%d] has no field", i)
+		}
+	}
+	return nil
+}
+
+// Tempo is the jsonData of the Tempo plugin.
+type Tempo struct {
+	HTTPOptions
+	TracesToLogsV2  *TracesToLogs    `json:"tracesToLogsV2,omitempty"`
+	TracesToMetrics *TracesToMetrics `json:"tracesToMetrics,omitempty"`
+	ServiceMap      *DatasourceLink  `json:"serviceMap,omitempty"`
+	LokiSearch      *DatasourceLink  `json:"lokiSearch,omitempty"`
+	NodeGraph       *Toggle          `json:"nodeGraph,omitempty"`
+	Search          *TempoSearch     `json:"search,omitempty"`
+}
+
+// DatasourceLink refers to another datasource by uid.
+type DatasourceLink struct {
+	DatasourceUID string `json:"datasourceUid"`
+}
+
+// Toggle enables an optional feature.
+type Toggle struct {
+	Enabled bool `json:"enabled"`
+}
+
+// TempoSearch configures the search tab of Tempo.
+type TempoSearch struct {
+	Hide bool `json:"hide,omitempty"`
+}
+
+// SpanTag maps a span attribute to a label of the linked datasource. Value is the label name,
+// Key is used if it is empty.
+type SpanTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// TracesToLogs links spans to the logs of a Loki, Elasticsearch or Splunk datasource.
+type TracesToLogs struct {
+	DatasourceUID      string    `json:"datasourceUid"`
+	Tags               []SpanTag `json:"tags,omitempty"`
+	SpanStartTimeShift string    `json:"spanStartTimeShift,omitempty"`
+	SpanEndTimeShift   string    `json:"spanEndTimeShift,omitempty"`
+	FilterByTraceID    bool      `json:"filterByTraceID,omitempty"`
+	FilterBySpanID     bool      `json:"filterBySpanID,omitempty"`
+	CustomQuery        bool      `json:"customQuery,omitempty"`
+	Query              string    `json:"query,omitempty"`
+}
+
+// TracesToMetrics links spans to queries of a Prometheus datasource.
+type TracesToMetrics struct {
+	DatasourceUID      string        `json:"datasourceUid"`
+	Tags               []SpanTag     `json:"tags,omitempty"`
+	SpanStartTimeShift string        `json:"spanStartTimeShift,omitempty"`
+	SpanEndTimeShift   string        `json:"spanEndTimeShift,omitempty"`
+	Queries            []MetricQuery `json:"queries,omitempty"`
+}
+
+// MetricQuery is a query linked from spans. $__tags in Query is replaced by the span tags.
+type MetricQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Validate implements JSONData.
+func (t Tempo) Validate() error {
+	if err := t.HTTPOptions.validate(); err != nil {
+		return err
+	}
+	if l := t.TracesToLogsV2; l != nil {
+		if l.DatasourceUID == "" {
+			return errors.New("tracesToLogsV2 has no datasourceUid")
+		}
+		if l.CustomQuery && l.Query == "" {
+			return errors.New("tracesToLogsV2 uses a custom query, but has no query")
+		}
+		if err := checkDuration("tracesToLogsV2.spanStartTimeShift", l.SpanStartTimeShift); err != nil {
+			return err
+		}
+		if err := checkDuration("tracesToLogsV2.spanEndTimeShift", l.SpanEndTimeShift); err != nil {
+			return err
+		}
+	}
+	if m := t.TracesToMetrics; m != nil {
+		if m.DatasourceUID == "" {
+			return errors.New("tracesToMetrics has no datasourceUid")
+		}
+		if err := checkDuration("tracesToMetrics.spanStartTimeShift", m.SpanStartTimeShift); err != nil {
+			return err
+		}
+		if err := checkDuration("tracesToMetrics.spanEndTimeShift", m.SpanEndTimeShift); err != nil {
+			return err
+		}
+		for i, q := range m.Queries {
+			if q.Query == "" {
+				return fmt.Errorf("tracesToMetrics.queries[%d] has no query", i)
+			}
+		}
+	}
+	if t.ServiceMap != nil && t.ServiceMap.DatasourceUID == "" {
+		return errors.New("serviceMap has no datasourceUid")
+	}
+	if t.LokiSearch != nil && t.LokiSearch.DatasourceUID == "" {
+		return errors.New("lokiSearch has no datasourceUid")
+	}
+	return nil
+}
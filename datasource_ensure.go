@@ -0,0 +1,184 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// EnsureAction tells what EnsureDatasource did.
+type EnsureAction string
+
+const (
+	EnsureCreated   EnsureAction = "created"
+	EnsureUpdated   EnsureAction = "updated"
+	EnsureUnchanged EnsureAction = "unchanged"
+)
+
+// EnsureDatasource makes sure a datasource as described by desired exists. The datasource is
+// looked up by desired.UID, if set, and then by desired.Name. It is created if missing and
+// updated only if it differs from desired.
+//
+// As Grafana never returns secure values, a secret of desired.SecureJSONData (or the legacy
+// Password and BasicAuthPassword) only causes an update if Grafana reports it as unset in
+// SecureJSONFields; a changed secret of an otherwise unchanged datasource is not detected.
+// When the datasource is updated, all desired secrets are sent.
+func (c *Client) EnsureDatasource(ctx context.Context, desired Datasource) (EnsureAction, error) {
+	if desired.Name == "" {
+		return "", errors.New("failed to ensure datasource, reason: missing name")
+	}
+	current, err := c.findDatasource(ctx, desired)
+	if err != nil {
+		return "", err
+	}
+
+	if current == nil {
+		desired.ID = 0
+		desired.Version = 0
+		if _, err = c.CreateDatasource(ctx, &desired); err != nil {
+			return "", err
+		}
+		return EnsureCreated, nil
+	}
+
+	changed, err := datasourceChanged(*current, desired)
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return EnsureUnchanged, nil
+	}
+	desired.ID = current.ID
+	desired.OrgID = current.OrgID
+	desired.Version = current.Version
+	if desired.UID == "" {
+		desired.UID = current.UID
+	}
+	if _, err = c.updateDatasource(ctx, current.UID, desired); err != nil {
+		return "", err
+	}
+	return EnsureUpdated, nil
+}
+
+// findDatasource returns the datasource matching ds by uid or name, or nil if there is none.
+func (c *Client) findDatasource(ctx context.Context, ds Datasource) (*Datasource, error) {
+	if ds.UID != "" {
+		current, err := c.GetDatasourceByUID(ctx, ds.UID)
+		if !IsNotFound(err) {
+			return current, err
+		}
+	}
+	current, err := c.GetDatasourceByName(ctx, ds.Name)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	return current, err
+}
+
+// datasourceChanged reports whether current, as read from Grafana, must be updated to match
+// desired. Optional fields that are not set in desired are not compared.
+func datasourceChanged(current, desired Datasource) (bool, error) {
+	if desired.UID != "" && desired.UID != current.UID ||
+		desired.Name != current.Name ||
+		desired.Type != current.Type ||
+		desired.Access != "" && desired.Access != current.Access ||
+		desired.URL != current.URL ||
+		!optionalEqual(desired.User, current.User) ||
+		!optionalEqual(desired.Database, current.Database) ||
+		!optionalEqual(desired.BasicAuth, current.BasicAuth) ||
+		!optionalEqual(desired.BasicAuthUser, current.BasicAuthUser) ||
+		desired.WithCredentials != current.WithCredentials ||
+		desired.IsDefault != current.IsDefault {
+		return true, nil
+	}
+
+	want, err := normalizeJSONData(desired.JSONData)
+	if err != nil {
+		return false, fmt.Errorf("failed to ensure datasource, reason: invalid jsonData: %w", err)
+	}
+	got, err := normalizeJSONData(current.JSONData)
+	if err != nil {
+		return false, fmt.Errorf("failed to ensure datasource, reason: invalid jsonData: %w", err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		return true, nil
+	}
+
+	secrets, err := secureKeys(desired)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range secrets {
+		if !current.SecureJSONFields[key] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func optionalEqual[T comparable](desired, current *T) bool {
+	return desired == nil || current != nil && *desired == *current
+}
+
+// normalizeJSONData round trips v through JSON, so typed and decoded values compare equal.
+// nil and empty objects are treated alike.
+func normalizeJSONData(v any) (any, error) {
+	if v == nil {
+		return map[string]any{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err = json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return map[string]any{}, nil
+	}
+	return out, nil
+}
+
+// secureKeys returns the keys of the secrets set in ds.
+func secureKeys(ds Datasource) ([]string, error) {
+	var keys []string
+	if ds.Password != nil {
+		keys = append(keys, "password")
+	}
+	if ds.BasicAuthPassword != nil {
+		keys = append(keys, "basicAuthPassword")
+	}
+	if ds.SecureJSONData != nil {
+		data, err := json.Marshal(ds.SecureJSONData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ensure datasource, reason: invalid secureJsonData: %w", err)
+		}
+		var secure map[string]any
+		if err = json.Unmarshal(data, &secure); err != nil {
+			return nil, fmt.Errorf("failed to ensure datasource, reason: secureJsonData must be an object: %w", err)
+		}
+		for k := range secure {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClient_EnsureDatasource(t *testing.T) {
+	datasources := map[string]*Datasource{}
+	srv := newDatasourceServer(t, datasources)
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	type jsonData struct {
+		SSLMode         string `json:"sslmode"`
+		PostgresVersion int    `json:"postgresVersion"`
+	}
+	user := "grafana"
+	desired := Datasource{
+		UID:            "pg",
+		Name:           "Postgres",
+		Type:           "grafana-postgresql-datasource",
+		Access:         "proxy",
+		URL:            "postgres:5432",
+		User:           &user,
+		JSONData:       jsonData{SSLMode: "disable", PostgresVersion: 1500},
+		SecureJSONData: map[string]string{"password": "secret"},
+	}
+	renamed := desired
+	renamed.UID = ""
+	renamed.JSONData = map[string]any{"sslmode": "require", "postgresVersion": 1500}
+	withCert := renamed
+	withCert.SecureJSONData = map[string]string{"password": "secret", "tlsCACert": "cert"}
+
+	tests := []struct {
+		name    string
+		desired Datasource
+		want    EnsureAction
+		version int
+	}{
+		{name: "Missing", desired: desired, want: EnsureCreated, version: 1},
+		{name: "Same", desired: desired, want: EnsureUnchanged, version: 1},
+		{name: "Changed secret is not detected", desired: func() Datasource {
+			ds := desired
+			ds.SecureJSONData = map[string]string{"password": "changed"}
+			return ds
+		}(), want: EnsureUnchanged, version: 1},
+		{name: "Changed jsonData found by name", desired: renamed, want: EnsureUpdated, version: 2},
+		{name: "Same found by name", desired: renamed, want: EnsureUnchanged, version: 2},
+		{name: "Unset secret", desired: withCert, want: EnsureUpdated, version: 3},
+		{name: "Unset secret is sent once", desired: withCert, want: EnsureUnchanged, version: 3},
+		{name: "Changed url", desired: func() Datasource {
+			ds := withCert
+			ds.URL = "postgres.db:5432"
+			return ds
+		}(), want: EnsureUpdated, version: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.EnsureDatasource(context.TODO(), tt.desired)
+			if err != nil {
+				t.Fatalf("EnsureDatasource() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EnsureDatasource() got = %v, want %v", got, tt.want)
+			}
+			ds, ok := datasources["pg"]
+			if len(datasources) != 1 || !ok {
+				t.Fatalf("datasources = %v", datasources)
+			}
+			if ds.Version != tt.version {
+				t.Errorf("version = %v, want %v", ds.Version, tt.version)
+			}
+		})
+	}
+
+	if fields := datasources["pg"].SecureJSONFields; !fields["password"] || !fields["tlsCACert"] {
+		t.Errorf("secureJsonFields = %v", fields)
+	}
+	if _, err = c.EnsureDatasource(context.TODO(), Datasource{Type: "prometheus"}); err == nil {
+		t.Errorf("EnsureDatasource() without name expected error")
+	}
+}
+
+func TestDatasourceChanged(t *testing.T) {
+	db := "app"
+	other := "other"
+	current := Datasource{
+		UID: "P1", Name: "Prometheus", Type: "prometheus", Access: "proxy", URL: "http://prometheus:9090",
+		Database: &db, JSONData: map[string]any{}, SecureJSONFields: map[string]bool{"basicAuthPassword": true},
+	}
+	tests := []struct {
+		name string
+		edit func(ds *Datasource)
+		want bool
+	}{
+		{name: "Same", edit: func(ds *Datasource) {}},
+		{name: "Unset optional fields", edit: func(ds *Datasource) { ds.UID, ds.Access, ds.Database = "", "", nil }},
+		{name: "Nil jsonData", edit: func(ds *Datasource) { ds.JSONData = nil }},
+		{name: "Set legacy secret", edit: func(ds *Datasource) { ds.BasicAuthPassword = &other }},
+		{name: "Unset legacy secret", edit: func(ds *Datasource) { ds.Password = &other }, want: true},
+		{name: "Changed database", edit: func(ds *Datasource) { ds.Database = &other }, want: true},
+		{name: "Changed default", edit: func(ds *Datasource) { ds.IsDefault = true }, want: true},
+		{name: "Changed jsonData", edit: func(ds *Datasource) { ds.JSONData = map[string]any{"httpMethod": "POST"} }, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := current
+			desired.SecureJSONFields = nil
+			tt.edit(&desired)
+			got, err := datasourceChanged(current, desired)
+			if err != nil {
+				t.Fatalf("datasourceChanged() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("datasourceChanged() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
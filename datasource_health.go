@@ -0,0 +1,195 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultHealthCheckConcurrency is the number of concurrent checks of CheckAllDatasources,
+// if none is given.
+const DefaultHealthCheckConcurrency = 4
+
+// HealthStatus is the status of a datasource health check.
+type HealthStatus string
+
+const (
+	HealthStatusOK      HealthStatus = "OK"
+	HealthStatusError   HealthStatus = "ERROR"
+	HealthStatusUnknown HealthStatus = "UNKNOWN"
+)
+
+// DatasourceHealth is the result of a datasource health check.
+type DatasourceHealth struct {
+	Status  HealthStatus `json:"status"`
+	Message string       `json:"message,omitempty"`
+	// Details are plugin specific, e.g. the verboseMessage of a failing Prometheus check.
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// OK reports whether Grafana could reach the backend of the datasource.
+func (h *DatasourceHealth) OK() bool {
+	return h != nil && h.Status == HealthStatusOK
+}
+
+// CheckDatasourceHealth asks Grafana to check whether the datasource with the given uid can
+// reach its backend. A failing check is not an error, but returned with HealthStatusError; an
+// error is returned if the check could not be run, e.g. the datasource does not exist.
+//
+// Only the health endpoints of the Grafana API are supported: the uid based one and, through
+// CheckDatasourceHealthByID, the id based one of older Grafana versions. Plugins without a
+// backend are tested by the Grafana frontend with plugin specific queries, which are not
+// replicated here, so their checks fail.
+// It reflects GET /api/datasources/uid/:uid/health API call.
+func (c *Client) CheckDatasourceHealth(ctx context.Context, uid string) (*DatasourceHealth, error) {
+	return c.checkDatasourceHealth(ctx, c.datasourceURL("uid", uid)+"/health")
+}
+
+// CheckDatasourceHealthByID is like CheckDatasourceHealth, but for Grafana versions that
+// address datasources by numeric id.
+// It reflects GET /api/datasources/:id/health API call.
+func (c *Client) CheckDatasourceHealthByID(ctx context.Context, id int) (*DatasourceHealth, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/datasources", strconv.Itoa(id), "health")
+	return c.checkDatasourceHealth(ctx, u.String())
+}
+
+func (c *Client) checkDatasourceHealth(ctx context.Context, u string) (*DatasourceHealth, error) {
+	resp, err := c.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	health := &DatasourceHealth{}
+	if err = decodeResponse(resp, health); err == nil {
+		return health, nil
+	}
+	// Grafana answers a failing check with 400, or 500 if the plugin failed, and the result as body.
+	if resp.statusCode == http.StatusBadRequest || resp.statusCode >= http.StatusInternalServerError {
+		failed := &DatasourceHealth{}
+		if json.Unmarshal(resp.body, failed) == nil && failed.Status != "" {
+			return failed, nil
+		}
+	}
+	return nil, err
+}
+
+// DatasourceHealthResult is the health check result of one datasource.
+type DatasourceHealthResult struct {
+	UID  string
+	Name string
+	Type string
+	// Health is nil if the check could not be run, see Err.
+	Health *DatasourceHealth
+	Err    error
+}
+
+// OK reports whether the check was run and succeeded.
+func (r DatasourceHealthResult) OK() bool {
+	return r.Err == nil && r.Health.OK()
+}
+
+// HealthReport is returned by CheckAllDatasources.
+type HealthReport struct {
+	// Results has one entry per datasource, in the order returned by ListDatasources.
+	Results []DatasourceHealthResult
+}
+
+// Healthy reports whether all datasources passed their check.
+func (r HealthReport) Healthy() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the results of datasources that did not pass their check.
+func (r HealthReport) Failed() []DatasourceHealthResult {
+	var failed []DatasourceHealthResult
+	for _, res := range r.Results {
+		if !res.OK() {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// String returns one line per datasource.
+func (r HealthReport) String() string {
+	var sb strings.Builder
+	for _, res := range r.Results {
+		status, msg := HealthStatusUnknown, ""
+		switch {
+		case res.Err != nil:
+			msg = res.Err.Error()
+		case res.Health != nil:
+			status, msg = res.Health.Status, res.Health.Message
+		}
+		_, _ = fmt.Fprintf(&sb, "%s (%s): %s", res.Name, res.Type, status)
+		if msg != "" {
+			sb.WriteString(": " + msg)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// CheckAllDatasources checks the health of all datasources of the current organization with at
+// most concurrency checks in flight, DefaultHealthCheckConcurrency if it is not positive.
+// Datasources are checked by uid, or by id if they have none or Grafana does not know the uid
+// based endpoint, as before Grafana 8. An error is only returned if the
+// datasources could not be listed; failed checks are part of the report.
+func (c *Client) CheckAllDatasources(ctx context.Context, concurrency int) (*HealthReport, error) {
+	list, err := c.ListDatasources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultHealthCheckConcurrency
+	}
+
+	report := &HealthReport{Results: make([]DatasourceHealthResult, len(list))}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(list)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ds := list[i]
+				res := DatasourceHealthResult{UID: ds.UID, Name: ds.Name, Type: ds.Type}
+				if ds.UID != "" {
+					res.Health, res.Err = c.CheckDatasourceHealth(ctx, ds.UID)
+				}
+				if ds.UID == "" || (IsNotFound(res.Err) && ds.ID > 0) {
+					res.Health, res.Err = c.CheckDatasourceHealthByID(ctx, int(ds.ID))
+				}
+				report.Results[i] = res
+			}
+		}()
+	}
+	for i := range list {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return report, nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CheckDatasourceHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus HealthStatus
+		wantErr    bool
+	}{
+		{
+			name:       "OK",
+			status:     http.StatusOK,
+			body:       `{"status":"OK","message":"Successfully queried the Prometheus API."}`,
+			wantStatus: HealthStatusOK,
+		},
+		{
+			name:       "Failing check",
+			status:     http.StatusBadRequest,
+			body:       `{"status":"ERROR","message":"There was an error returned querying the Prometheus API.","details":{"verboseMessage":"connection refused"}}`,
+			wantStatus: HealthStatusError,
+		},
+		{
+			name:       "Plugin error",
+			status:     http.StatusInternalServerError,
+			body:       `{"status":"ERROR","message":"Plugin health check failed"}`,
+			wantStatus: HealthStatusError,
+		},
+		{
+			name:    "Not found",
+			status:  http.StatusNotFound,
+			body:    `{"message":"Data source not found"}`,
+			wantErr: true,
+		},
+		{
+			name:    "Bad request without result",
+			status:  http.StatusBadRequest,
+			body:    `{"message":"bad request"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, reqs := newRecordingServer(t, tt.status, tt.body)
+			c, err := NewClient(srv.URL, WithAuth(validAuth))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			got, err := c.CheckDatasourceHealth(context.TODO(), "P1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckDatasourceHealth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if p := (*reqs)[0].Path; p != "/api/datasources/uid/P1/health" {
+				t.Errorf("path = %v", p)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Status != tt.wantStatus || got.OK() != (tt.wantStatus == HealthStatusOK) {
+				t.Errorf("CheckDatasourceHealth() got = %+v", got)
+			}
+		})
+	}
+
+	srv, reqs := newRecordingServer(t, http.StatusOK, `{"status":"OK","message":"Data source is working"}`)
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	got, err := c.CheckDatasourceHealthByID(context.TODO(), 7)
+	if err != nil || !got.OK() {
+		t.Fatalf("CheckDatasourceHealthByID() got = %+v, error = %v", got, err)
+	}
+	if p := (*reqs)[0].Path; p != "/api/datasources/7/health" {
+		t.Errorf("path = %v", p)
+	}
+}
+
+func TestClient_CheckAllDatasources(t *testing.T) {
+	const concurrency = 2
+	var inFlight, maxInFlight atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/datasources", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+  {"id":1,"uid":"a","name":"A","type":"prometheus"},
+  {"id":2,"uid":"b","name":"B","type":"loki"},
+  {"id":3,"uid":"gone","name":"Gone","type":"loki"},
+  {"id":4,"uid":"d","name":"D","type":"tempo"},
+  {"id":5,"name":"Legacy","type":"graphite"},
+  {"id":6,"uid":"old","name":"Old","type":"influxdb"}
+]`))
+	})
+	health := func(w http.ResponseWriter, ok bool) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if ok {
+			_, _ = w.Write([]byte(`{"status":"OK","message":"ok"}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"ERROR","message":"connection refused"}`))
+	}
+	mux.HandleFunc("GET /api/datasources/uid/{uid}/health", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("uid") {
+		case "gone", "old":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Data source not found"}`))
+		default:
+			health(w, r.PathValue("uid") != "b")
+		}
+	})
+	mux.HandleFunc("GET /api/datasources/{id}/health", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("id") {
+		case "5", "6":
+			health(w, true)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Data source not found"}`))
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	report, err := c.CheckAllDatasources(context.TODO(), concurrency)
+	if err != nil {
+		t.Fatalf("CheckAllDatasources() error = %v", err)
+	}
+	if n := maxInFlight.Load(); n > concurrency {
+		t.Errorf("checks in flight = %d, want at most %d", n, concurrency)
+	}
+
+	var names []string
+	for _, res := range report.Results {
+		names = append(names, res.Name)
+	}
+	if len(names) != 6 || names[0] != "A" || names[4] != "Legacy" || names[5] != "Old" {
+		t.Errorf("CheckAllDatasources() results = %v", names)
+	}
+	failed := report.Failed()
+	if report.Healthy() || len(failed) != 2 || failed[0].Name != "B" || failed[1].Name != "Gone" {
+		t.Fatalf("Failed() got = %+v", failed)
+	}
+	if failed[0].Err != nil || failed[0].Health.Message != "connection refused" {
+		t.Errorf("failed check got = %+v", failed[0])
+	}
+	if !IsNotFound(failed[1].Err) {
+		t.Errorf("check of missing datasource error = %v, want not found", failed[1].Err)
+	}
+	if want := "A (prometheus): OK: ok\n"; report.String()[:len(want)] != want {
+		t.Errorf("String() got = %q", report.String())
+	}
+}
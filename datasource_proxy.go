@@ -0,0 +1,83 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProxyRequest is a request sent to the backend of a datasource through Grafana.
+type ProxyRequest struct {
+	// Method defaults to GET.
+	Method string
+	// Path is relative to the URL of the datasource, e.g. "api/v1/query" for Prometheus.
+	Path  string
+	Query url.Values
+	Body  []byte
+	// ContentType of Body, defaults to application/json.
+	ContentType string
+}
+
+// ProxyResponse is the response of the backend of a datasource.
+type ProxyResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// DatasourceProxy sends req to the backend of the datasource with the given uid through Grafana,
+// which adds the credentials of the datasource, so only Grafana credentials are needed. Like all
+// Client methods, it returns an *APIError if the backend or Grafana answer with a non-2xx status;
+// its Body holds the answer of the backend.
+// It reflects the /api/datasources/proxy/uid/:uid/* API calls.
+func (c *Client) DatasourceProxy(ctx context.Context, uid string, req ProxyRequest) (*ProxyResponse, error) {
+	if uid == "" {
+		return nil, errors.New("failed to proxy datasource request, reason: missing uid")
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	u := c.datasourceURL("proxy/uid", uid)
+	if p := strings.TrimPrefix(req.Path, "/"); p != "" {
+		u += "/" + (&url.URL{Path: p}).EscapedPath()
+	}
+	if len(req.Query) > 0 {
+		u += "?" + req.Query.Encode()
+	}
+	resp, err := c.doRaw(ctx, method, u, req.Body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return &ProxyResponse{
+		StatusCode: resp.statusCode,
+		Header:     resp.header,
+		Body:       resp.body,
+	}, nil
+}
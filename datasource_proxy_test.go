@@ -0,0 +1,103 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestClient_DatasourceProxy(t *testing.T) {
+	type seen struct {
+		method, path, rawQuery, contentType, orgID, user, body string
+	}
+	tests := []struct {
+		name     string
+		req      ProxyRequest
+		status   int
+		want     seen
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "GET with query",
+			req:      ProxyRequest{Path: "/api/v1/query", Query: url.Values{"query": {`up{job="api"}`}}},
+			status:   http.StatusOK,
+			want:     seen{method: http.MethodGet, path: "/api/datasources/proxy/uid/P1/api/v1/query", rawQuery: "query=up%7Bjob%3D%22api%22%7D", orgID: "2", user: "admin"},
+			wantBody: `{"status":"success"}`,
+		},
+		{
+			name: "POST with raw body",
+			req: ProxyRequest{
+				Method:      http.MethodPost,
+				Path:        "_msearch",
+				Body:        []byte("{}\n{\"query\":{}}\n"),
+				ContentType: "application/x-ndjson",
+			},
+			status:   http.StatusOK,
+			want:     seen{method: http.MethodPost, path: "/api/datasources/proxy/uid/P1/_msearch", contentType: "application/x-ndjson", orgID: "2", user: "admin", body: "{}\n{\"query\":{}}\n"},
+			wantBody: `{"status":"success"}`,
+		},
+		{
+			name:    "Backend error",
+			req:     ProxyRequest{Path: "api/v1/query"},
+			status:  http.StatusBadRequest,
+			want:    seen{method: http.MethodGet, path: "/api/datasources/proxy/uid/P1/api/v1/query", orgID: "2", user: "admin"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got seen
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				user, _, _ := r.BasicAuth()
+				got = seen{
+					method:      r.Method,
+					path:        r.URL.Path,
+					rawQuery:    r.URL.RawQuery,
+					contentType: r.Header.Get("Content-Type"),
+					orgID:       r.Header.Get(orgIDHeader),
+					user:        user,
+					body:        string(data),
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"status":"success"}`))
+			}))
+			defer srv.Close()
+
+			c, err := NewClient(srv.URL, WithAuth(validAuth))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			resp, err := c.WithOrgID(2).DatasourceProxy(context.TODO(), "P1", tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DatasourceProxy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DatasourceProxy() sent = %+v, want %+v", got, tt.want)
+			}
+			if !tt.wantErr && string(resp.Body) != tt.wantBody {
+				t.Errorf("DatasourceProxy() body = %s, want %s", resp.Body, tt.wantBody)
+			}
+		})
+	}
+}
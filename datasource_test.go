@@ -0,0 +1,241 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+)
+
+// newDatasourceServer returns a server keeping datasources in memory, keyed by uid. Like Grafana,
+// it never returns secureJsonData, but reports the secure fields that are set in secureJsonFields.
+func newDatasourceServer(t *testing.T, datasources map[string]*Datasource) *httptest.Server {
+	t.Helper()
+	byName := func(name string) *Datasource {
+		for _, ds := range datasources {
+			if ds.Name == name {
+				return ds
+			}
+		}
+		return nil
+	}
+	notFound := func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Data source not found"}`))
+	}
+	read := func(ds *Datasource) Datasource {
+		out := *ds
+		out.SecureJSONData = nil
+		return out
+	}
+	setSecureFields := func(ds *Datasource, secure any) {
+		fields, _ := secure.(map[string]any)
+		for k := range fields {
+			if ds.SecureJSONFields == nil {
+				ds.SecureJSONFields = map[string]bool{}
+			}
+			ds.SecureJSONFields[k] = true
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/datasources", func(w http.ResponseWriter, r *http.Request) {
+		list := []Datasource{}
+		for _, ds := range datasources {
+			list = append(list, read(ds))
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("POST /api/datasources", func(w http.ResponseWriter, r *http.Request) {
+		ds := &Datasource{}
+		_ = json.NewDecoder(r.Body).Decode(ds)
+		if byName(ds.Name) != nil {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"data source with the same name already exists"}`))
+			return
+		}
+		ds.ID = uint(len(datasources) + 1)
+		if ds.UID == "" {
+			ds.UID = fmt.Sprintf("ds%d", ds.ID)
+		}
+		ds.Version = 1
+		setSecureFields(ds, ds.SecureJSONData)
+		datasources[ds.UID] = ds
+		_, _ = fmt.Fprintf(w, `{"id":%d,"uid":%q,"name":%q,"message":"Datasource added"}`, ds.ID, ds.UID, ds.Name)
+	})
+	mux.HandleFunc("GET /api/datasources/uid/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		ds, ok := datasources[r.PathValue("uid")]
+		if !ok {
+			notFound(w)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(read(ds))
+	})
+	mux.HandleFunc("GET /api/datasources/name/{name}", func(w http.ResponseWriter, r *http.Request) {
+		ds := byName(r.PathValue("name"))
+		if ds == nil {
+			notFound(w)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(read(ds))
+	})
+	mux.HandleFunc("GET /api/datasources/id/{name}", func(w http.ResponseWriter, r *http.Request) {
+		ds := byName(r.PathValue("name"))
+		if ds == nil {
+			notFound(w)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"id":%d}`, ds.ID)
+	})
+	mux.HandleFunc("PUT /api/datasources/uid/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		cur, ok := datasources[r.PathValue("uid")]
+		if !ok {
+			notFound(w)
+			return
+		}
+		ds := &Datasource{}
+		_ = json.NewDecoder(r.Body).Decode(ds)
+		if ds.Version != 0 && ds.Version != cur.Version {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"Datasource has already been updated by someone else. Please reload and try again"}`))
+			return
+		}
+		ds.ID, ds.UID, ds.Version = cur.ID, cur.UID, cur.Version+1
+		ds.SecureJSONFields = cur.SecureJSONFields
+		setSecureFields(ds, ds.SecureJSONData)
+		datasources[ds.UID] = ds
+		_, _ = fmt.Fprintf(w, `{"id":%d,"name":%q,"message":"Datasource updated"}`, ds.ID, ds.Name)
+	})
+	deleteDatasource := func(w http.ResponseWriter, ds *Datasource) {
+		if ds == nil {
+			notFound(w)
+			return
+		}
+		delete(datasources, ds.UID)
+		_, _ = w.Write([]byte(`{"message":"Data source deleted"}`))
+	}
+	mux.HandleFunc("DELETE /api/datasources/uid/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		deleteDatasource(w, datasources[r.PathValue("uid")])
+	})
+	mux.HandleFunc("DELETE /api/datasources/name/{name}", func(w http.ResponseWriter, r *http.Request) {
+		deleteDatasource(w, byName(r.PathValue("name")))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_Datasources(t *testing.T) {
+	datasources := map[string]*Datasource{
+		"prom": {ID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus", URL: "http://prometheus:9090", Version: 3, IsDefault: true},
+		"pg":   {ID: 2, UID: "pg", Name: "db/primary", Type: "grafana-postgresql-datasource", SecureJSONFields: map[string]bool{"password": true}, Version: 1},
+	}
+	srv := newDatasourceServer(t, datasources)
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	ctx := context.TODO()
+
+	list, err := c.ListDatasources(ctx)
+	if err != nil {
+		t.Fatalf("ListDatasources() error = %v", err)
+	}
+	if len(list) != 2 || list[0].UID != "prom" || list[1].UID != "pg" {
+		t.Errorf("ListDatasources() got = %+v", list)
+	}
+
+	ds, err := c.GetDatasourceByUID(ctx, "prom")
+	if err != nil {
+		t.Fatalf("GetDatasourceByUID() error = %v", err)
+	}
+	if ds.Name != "Prometheus" || ds.Version != 3 || !ds.IsDefault {
+		t.Errorf("GetDatasourceByUID() got = %+v", ds)
+	}
+	if _, err = c.GetDatasourceByUID(ctx, "missing"); !IsNotFound(err) {
+		t.Errorf("GetDatasourceByUID() error = %v, want not found", err)
+	}
+
+	ds, err = c.GetDatasourceByName(ctx, "db/primary")
+	if err != nil {
+		t.Fatalf("GetDatasourceByName() error = %v", err)
+	}
+	if ds.UID != "pg" || !ds.SecureJSONFields["password"] {
+		t.Errorf("GetDatasourceByName() got = %+v", ds)
+	}
+	id, err := c.GetDatasourceIDByName(ctx, "db/primary")
+	if err != nil {
+		t.Fatalf("GetDatasourceIDByName() error = %v", err)
+	}
+	if id != 2 {
+		t.Errorf("GetDatasourceIDByName() got = %v, want 2", id)
+	}
+
+	update := datasources["prom"]
+	updated := *update
+	updated.URL = "http://prometheus.monitoring:9090"
+	if _, err = c.UpdateDatasourceByUID(ctx, updated); err != nil {
+		t.Fatalf("UpdateDatasourceByUID() error = %v", err)
+	}
+	if got := datasources["prom"]; got.URL != updated.URL || got.Version != 4 {
+		t.Errorf("UpdateDatasourceByUID() stored = %+v", got)
+	}
+	if _, err = c.UpdateDatasourceByUID(ctx, updated); !IsConflict(err) {
+		t.Errorf("UpdateDatasourceByUID() with stale version error = %v, want conflict", err)
+	}
+	if _, err = c.UpdateDatasourceByUID(ctx, Datasource{Name: "Prometheus"}); err == nil {
+		t.Errorf("UpdateDatasourceByUID() without uid expected error")
+	}
+
+	if _, err = c.DeleteDatasourceByName(ctx, "db/primary"); err != nil {
+		t.Fatalf("DeleteDatasourceByName() error = %v", err)
+	}
+	if _, err = c.DeleteDatasourceByUID(ctx, "prom"); err != nil {
+		t.Fatalf("DeleteDatasourceByUID() error = %v", err)
+	}
+	if len(datasources) != 0 {
+		t.Errorf("datasources left = %v", datasources)
+	}
+	if _, err = c.DeleteDatasourceByUID(ctx, "prom"); !IsNotFound(err) {
+		t.Errorf("DeleteDatasourceByUID() error = %v, want not found", err)
+	}
+}
+
+func TestClient_datasourceURL(t *testing.T) {
+	c, err := NewClient("http://grafana.example.com/sub%20path/")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	tests := []struct {
+		kind, key string
+		want      string
+	}{
+		{kind: "uid", key: "P1", want: "http://grafana.example.com/sub%20path/api/datasources/uid/P1"},
+		{kind: "name", key: "db/primary ?", want: "http://grafana.example.com/sub%20path/api/datasources/name/db%2Fprimary%20%3F"},
+	}
+	for _, tt := range tests {
+		if got := c.datasourceURL(tt.kind, tt.key); got != tt.want {
+			t.Errorf("datasourceURL() got = %v, want %v", got, tt.want)
+		}
+	}
+}
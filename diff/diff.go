@@ -0,0 +1,444 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff compares dashboard models semantically. Both dashboards are normalized with
+// normalize.DefaultRules first, so volatile members and default values are ignored. Panels are
+// matched by id instead of by position, variables by name and queries by refId, and missing, null
+// and empty values are considered equal.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/internal/jsonobj"
+	"go.openviz.dev/grafana-sdk/normalize"
+)
+
+// Kind is the kind of a change.
+type Kind string
+
+const (
+	DashboardChanged Kind = "DashboardChanged"
+	PanelAdded       Kind = "PanelAdded"
+	PanelRemoved     Kind = "PanelRemoved"
+	PanelMoved       Kind = "PanelMoved"
+	PanelChanged     Kind = "PanelChanged"
+	QueryAdded       Kind = "QueryAdded"
+	QueryRemoved     Kind = "QueryRemoved"
+	QueryChanged     Kind = "QueryChanged"
+	ThresholdChanged Kind = "ThresholdChanged"
+	VariableAdded    Kind = "VariableAdded"
+	VariableRemoved  Kind = "VariableRemoved"
+	VariableChanged  Kind = "VariableChanged"
+)
+
+// Field is a changed member of the object a Change refers to.
+type Field struct {
+	// Path is the dot separated path of the member relative to the object, e.g. options.legend.displayMode.
+	// It is empty if the whole object was added or removed.
+	Path string
+	// Old and New are the JSON encoded values. They are nil if the member is missing or empty.
+	Old json.RawMessage
+	New json.RawMessage
+}
+
+// Change is a change of the dashboard, a panel, a query or a variable.
+type Change struct {
+	Kind Kind
+	// Path locates the changed object, e.g. panels[id=2].targets[refId=A] or templating.list[name=ns].
+	// It is empty for changes of the dashboard itself.
+	Path string
+	// Title is the title of the changed panel or the name of the changed variable.
+	Title  string
+	Fields []Field
+}
+
+// Report lists the changes between two dashboards.
+type Report struct {
+	Changes []Change
+}
+
+// Equal reports whether the dashboards are semantically equal.
+func (r *Report) Equal() bool {
+	return len(r.Changes) == 0
+}
+
+// Dashboards returns the changes that turn the dashboard old into new.
+func Dashboards(old, new *sdk.Dashboard) (*Report, error) {
+	if old == nil {
+		old = &sdk.Dashboard{}
+	}
+	if new == nil {
+		new = &sdk.Dashboard{}
+	}
+	old, err := normalize.Normalize(old, normalize.DefaultRules())
+	if err != nil {
+		return nil, err
+	}
+	if new, err = normalize.Normalize(new, normalize.DefaultRules()); err != nil {
+		return nil, err
+	}
+	oldObj, err := jsonobj.From(old)
+	if err != nil {
+		return nil, err
+	}
+	newObj, err := jsonobj.From(new)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range []map[string]any{oldObj, newObj} {
+		for _, key := range []string{"panels", "templating"} {
+			delete(obj, key)
+		}
+	}
+
+	r := &Report{}
+	if fields := diffFields(oldObj, newObj); len(fields) > 0 {
+		r.Changes = append(r.Changes, Change{Kind: DashboardChanged, Fields: fields})
+	}
+	if err = r.diffPanels(old, new); err != nil {
+		return nil, err
+	}
+	if err = r.diffVariables(old, new); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type panelEntry struct {
+	key   string
+	panel *sdk.Panel
+	row   string
+}
+
+// panelEntries returns the panels of d, including the ones nested in collapsed rows, keyed by id.
+func panelEntries(d *sdk.Dashboard) []panelEntry {
+	var entries []panelEntry
+	row := ""
+	for i := range d.Panels {
+		p := &d.Panels[i]
+		if !p.IsRow() {
+			entries = append(entries, panelEntry{key: panelKey(p), panel: p, row: row})
+			continue
+		}
+		// Panels following an expanded row and the panels nested in a collapsed row belong to the row.
+		row = p.Title
+		entries = append(entries, panelEntry{key: panelKey(p), panel: p})
+		for j := range p.Panels {
+			entries = append(entries, panelEntry{key: panelKey(&p.Panels[j]), panel: &p.Panels[j], row: row})
+		}
+	}
+	return entries
+}
+
+func panelKey(p *sdk.Panel) string {
+	if p.ID != 0 {
+		return "id=" + strconv.Itoa(p.ID)
+	}
+	return "title=" + strconv.Quote(p.Title)
+}
+
+func (r *Report) diffPanels(old, new *sdk.Dashboard) error {
+	oldEntries := panelEntries(old)
+	oldByKey := map[string]panelEntry{}
+	for _, e := range oldEntries {
+		oldByKey[e.key] = e
+	}
+	seen := map[string]bool{}
+	for _, e := range panelEntries(new) {
+		path := "panels[" + e.key + "]"
+		seen[e.key] = true
+		o, ok := oldByKey[e.key]
+		if !ok {
+			c, err := wholeChange(PanelAdded, path, e.panel.Title, nil, e.panel)
+			if err != nil {
+				return err
+			}
+			r.Changes = append(r.Changes, c)
+			continue
+		}
+		if err := r.diffPanel(path, o, e); err != nil {
+			return err
+		}
+	}
+	for _, o := range oldEntries {
+		if seen[o.key] {
+			continue
+		}
+		c, err := wholeChange(PanelRemoved, "panels["+o.key+"]", o.panel.Title, o.panel, nil)
+		if err != nil {
+			return err
+		}
+		r.Changes = append(r.Changes, c)
+	}
+	return nil
+}
+
+func (r *Report) diffPanel(path string, old, new panelEntry) error {
+	var moved []Field
+	if old.panel.GridPos != new.panel.GridPos {
+		moved = append(moved, Field{Path: "gridPos", Old: mustJSON(old.panel.GridPos), New: mustJSON(new.panel.GridPos)})
+	}
+	if old.row != new.row {
+		moved = append(moved, Field{Path: "row", Old: rowJSON(old.row), New: rowJSON(new.row)})
+	}
+	if len(moved) > 0 {
+		r.Changes = append(r.Changes, Change{Kind: PanelMoved, Path: path, Title: new.panel.Title, Fields: moved})
+	}
+
+	oldObj, err := jsonobj.From(old.panel)
+	if err != nil {
+		return err
+	}
+	newObj, err := jsonobj.From(new.panel)
+	if err != nil {
+		return err
+	}
+	oldThresholds := takeThresholds(oldObj)
+	newThresholds := takeThresholds(newObj)
+	for _, obj := range []map[string]any{oldObj, newObj} {
+		for _, key := range []string{"id", "gridPos", "panels", "targets"} {
+			delete(obj, key)
+		}
+	}
+	if fields := diffFields(oldObj, newObj); len(fields) > 0 {
+		r.Changes = append(r.Changes, Change{Kind: PanelChanged, Path: path, Title: new.panel.Title, Fields: fields})
+	}
+	if fields := diffValue("", oldThresholds, newThresholds, nil); len(fields) > 0 {
+		r.Changes = append(r.Changes, Change{Kind: ThresholdChanged, Path: path + ".fieldConfig.defaults.thresholds", Title: new.panel.Title, Fields: fields})
+	}
+	return r.diffTargets(path, new.panel.Title, old.panel.Targets, new.panel.Targets)
+}
+
+// takeThresholds removes the default thresholds from the field config of the panel object p and returns them.
+func takeThresholds(p map[string]any) any {
+	fc, _ := p["fieldConfig"].(map[string]any)
+	defaults, _ := fc["defaults"].(map[string]any)
+	thresholds := defaults["thresholds"]
+	delete(defaults, "thresholds")
+	return thresholds
+}
+
+func (r *Report) diffTargets(panelPath, title string, old, new []sdk.Target) error {
+	key := func(i int, t *sdk.Target) string {
+		if t.RefID != "" {
+			return "refId=" + t.RefID
+		}
+		return strconv.Itoa(i)
+	}
+	oldByKey := map[string]*sdk.Target{}
+	for i := range old {
+		oldByKey[key(i, &old[i])] = &old[i]
+	}
+	seen := map[string]bool{}
+	for i := range new {
+		k := key(i, &new[i])
+		path := panelPath + ".targets[" + k + "]"
+		seen[k] = true
+		o, ok := oldByKey[k]
+		if !ok {
+			c, err := wholeChange(QueryAdded, path, title, nil, &new[i])
+			if err != nil {
+				return err
+			}
+			r.Changes = append(r.Changes, c)
+			continue
+		}
+		oldObj, err := jsonobj.From(o)
+		if err != nil {
+			return err
+		}
+		newObj, err := jsonobj.From(&new[i])
+		if err != nil {
+			return err
+		}
+		if fields := diffFields(oldObj, newObj); len(fields) > 0 {
+			r.Changes = append(r.Changes, Change{Kind: QueryChanged, Path: path, Title: title, Fields: fields})
+		}
+	}
+	for i := range old {
+		k := key(i, &old[i])
+		if seen[k] {
+			continue
+		}
+		c, err := wholeChange(QueryRemoved, panelPath+".targets["+k+"]", title, &old[i], nil)
+		if err != nil {
+			return err
+		}
+		r.Changes = append(r.Changes, c)
+	}
+	return nil
+}
+
+func variables(d *sdk.Dashboard) []sdk.Variable {
+	if d.Templating == nil {
+		return nil
+	}
+	return d.Templating.List
+}
+
+func (r *Report) diffVariables(old, new *sdk.Dashboard) error {
+	oldVars := variables(old)
+	oldByName := map[string]*sdk.Variable{}
+	for i := range oldVars {
+		oldByName[oldVars[i].Name] = &oldVars[i]
+	}
+	newVars := variables(new)
+	seen := map[string]bool{}
+	for i := range newVars {
+		v := &newVars[i]
+		path := "templating.list[name=" + v.Name + "]"
+		seen[v.Name] = true
+		o, ok := oldByName[v.Name]
+		if !ok {
+			c, err := wholeChange(VariableAdded, path, v.Name, nil, v)
+			if err != nil {
+				return err
+			}
+			r.Changes = append(r.Changes, c)
+			continue
+		}
+		oldObj, err := jsonobj.From(o)
+		if err != nil {
+			return err
+		}
+		newObj, err := jsonobj.From(v)
+		if err != nil {
+			return err
+		}
+		if fields := diffFields(oldObj, newObj); len(fields) > 0 {
+			r.Changes = append(r.Changes, Change{Kind: VariableChanged, Path: path, Title: v.Name, Fields: fields})
+		}
+	}
+	for i := range oldVars {
+		v := &oldVars[i]
+		if seen[v.Name] {
+			continue
+		}
+		c, err := wholeChange(VariableRemoved, "templating.list[name="+v.Name+"]", v.Name, v, nil)
+		if err != nil {
+			return err
+		}
+		r.Changes = append(r.Changes, c)
+	}
+	return nil
+}
+
+// wholeChange returns a change adding or removing a whole object.
+func wholeChange(kind Kind, path, title string, old, new any) (Change, error) {
+	f := Field{}
+	for _, v := range []struct {
+		value any
+		out   *json.RawMessage
+	}{{old, &f.Old}, {new, &f.New}} {
+		if v.value == nil || reflect.ValueOf(v.value).IsNil() {
+			continue
+		}
+		data, err := json.Marshal(v.value)
+		if err != nil {
+			return Change{}, err
+		}
+		*v.out = data
+	}
+	return Change{Kind: kind, Path: path, Title: title, Fields: []Field{f}}, nil
+}
+
+func diffFields(old, new map[string]any) []Field {
+	return diffValue("", old, new, nil)
+}
+
+// diffValue appends the differences between the generic JSON values old and new found at path to fields.
+func diffValue(path string, old, new any, fields []Field) []Field {
+	if jsonobj.IsEmpty(old) && jsonobj.IsEmpty(new) {
+		return fields
+	}
+	switch o := old.(type) {
+	case map[string]any:
+		if n, ok := new.(map[string]any); ok {
+			keys := map[string]bool{}
+			for k := range o {
+				keys[k] = true
+			}
+			for k := range n {
+				keys[k] = true
+			}
+			sorted := make([]string, 0, len(keys))
+			for k := range keys {
+				sorted = append(sorted, k)
+			}
+			sort.Strings(sorted)
+			for _, k := range sorted {
+				fields = diffValue(join(path, k), o[k], n[k], fields)
+			}
+			return fields
+		}
+	case []any:
+		if n, ok := new.([]any); ok && len(o) == len(n) {
+			for i := range o {
+				fields = diffValue(fmt.Sprintf("%s[%d]", path, i), o[i], n[i], fields)
+			}
+			return fields
+		}
+	case json.Number:
+		if n, ok := new.(json.Number); ok {
+			of, oerr := o.Float64()
+			nf, nerr := n.Float64()
+			if oerr == nil && nerr == nil && of == nf {
+				return fields
+			}
+		}
+	}
+	if reflect.DeepEqual(old, new) {
+		return fields
+	}
+	return append(fields, Field{Path: path, Old: optionalJSON(old), New: optionalJSON(new)})
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// optionalJSON encodes v, returning nil for empty values.
+func optionalJSON(v any) json.RawMessage {
+	if jsonobj.IsEmpty(v) {
+		return nil
+	}
+	return mustJSON(v)
+}
+
+// rowJSON encodes the title of a row, returning nil for panels outside of rows.
+func rowJSON(title string) json.RawMessage {
+	if title == "" {
+		return nil
+	}
+	return mustJSON(title)
+}
+
+func mustJSON(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
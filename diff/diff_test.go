@@ -0,0 +1,149 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/internal/testutil"
+)
+
+const base = `{
+  "id": 1, "uid": "pg", "version": 3, "iteration": 1638177476998, "title": "Postgres",
+  "templating": {"list": [{"name": "ns", "type": "query", "query": "label_values(ns)"}]},
+  "panels": [
+    {"id": 1, "type": "row", "title": "General", "gridPos": {"h": 1, "w": 24, "x": 0, "y": 0}},
+    {"id": 2, "type": "timeseries", "title": "CPU", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 1},
+     "fieldConfig": {"defaults": {"thresholds": {"mode": "absolute", "steps": [{"color": "green", "value": null}, {"color": "red", "value": 80}]}}},
+     "targets": [{"refId": "A", "expr": "rate(cpu[5m])"}]},
+    {"id": 3, "type": "stat", "title": "Uptime", "gridPos": {"h": 8, "w": 12, "x": 12, "y": 1}, "options": {"colorMode": "value"}}
+  ]
+}`
+
+type changeKey struct {
+	Kind Kind
+	Path string
+}
+
+func TestDashboards(t *testing.T) {
+	tests := []struct {
+		name string
+		new  string
+		want []changeKey
+	}{
+		{
+			name: "Volatile fields, panel order and empty values",
+			new: `{
+  "id": 9, "uid": "pg", "version": 4, "iteration": 1, "title": "Postgres", "links": [],
+  "templating": {"list": [{"name": "ns", "type": "query", "query": "label_values(ns)", "options": []}]},
+  "panels": [
+    {"id": 1, "type": "row", "title": "General", "gridPos": {"h": 1, "w": 24, "x": 0, "y": 0}},
+    {"id": 3, "type": "stat", "title": "Uptime", "gridPos": {"h": 8, "w": 12, "x": 12, "y": 1}, "options": {"colorMode": "value"}, "transformations": null},
+    {"id": 2, "type": "timeseries", "title": "CPU", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 1},
+     "fieldConfig": {"defaults": {"thresholds": {"mode": "absolute", "steps": [{"color": "green", "value": null}, {"color": "red", "value": 80.0}]}}},
+     "targets": [{"refId": "A", "expr": "rate(cpu[5m])"}]}
+  ]
+}`,
+		},
+		{
+			name: "Structured changes",
+			new: `{
+  "uid": "pg", "title": "PostgreSQL",
+  "templating": {"list": [{"name": "ns", "type": "query", "query": "label_values(namespace)"}, {"name": "db", "type": "custom", "query": "a,b"}]},
+  "panels": [
+    {"id": 1, "type": "row", "title": "General", "gridPos": {"h": 1, "w": 24, "x": 0, "y": 0}},
+    {"id": 2, "type": "timeseries", "title": "CPU", "gridPos": {"h": 8, "w": 24, "x": 0, "y": 1},
+     "fieldConfig": {"defaults": {"thresholds": {"mode": "absolute", "steps": [{"color": "green", "value": null}, {"color": "red", "value": 90}]}}},
+     "targets": [{"refId": "A", "expr": "rate(cpu[1m])"}, {"refId": "B", "expr": "up"}]},
+    {"id": 4, "type": "text", "title": "Notes", "gridPos": {"h": 8, "w": 24, "x": 0, "y": 9}}
+  ]
+}`,
+			want: []changeKey{
+				{DashboardChanged, ""},
+				{PanelMoved, "panels[id=2]"},
+				{ThresholdChanged, "panels[id=2].fieldConfig.defaults.thresholds"},
+				{QueryChanged, "panels[id=2].targets[refId=A]"},
+				{QueryAdded, "panels[id=2].targets[refId=B]"},
+				{PanelAdded, "panels[id=4]"},
+				{PanelRemoved, "panels[id=3]"},
+				{VariableChanged, "templating.list[name=ns]"},
+				{VariableAdded, "templating.list[name=db]"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Dashboards(testutil.MustUnmarshal[sdk.Dashboard](t, base), testutil.MustUnmarshal[sdk.Dashboard](t, tt.new))
+			if err != nil {
+				t.Fatalf("Dashboards() error = %v", err)
+			}
+			var got []changeKey
+			for _, c := range r.Changes {
+				got = append(got, changeKey{c.Kind, c.Path})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dashboards() got = %v, want %v\n%s", got, tt.want, r.Unified())
+			}
+			if r.Equal() != (len(tt.want) == 0) {
+				t.Errorf("Equal() got = %v", r.Equal())
+			}
+		})
+	}
+}
+
+func TestReport_Unified(t *testing.T) {
+	old := testutil.MustUnmarshal[sdk.Dashboard](t, `{"title":"Postgres","panels":[{"id":2,"type":"stat","title":"CPU","options":{"colorMode":"value"}},{"id":3,"type":"text","title":"Notes"}]}`)
+	new := testutil.MustUnmarshal[sdk.Dashboard](t, `{"title":"Postgres","panels":[{"id":2,"type":"stat","title":"CPU","options":{"colorMode":"background"}}]}`)
+	r, err := Dashboards(old, new)
+	if err != nil {
+		t.Fatalf("Dashboards() error = %v", err)
+	}
+	want := `@@ panel changed: panels[id=2] "CPU" @@
+-options.colorMode: "value"
++options.colorMode: "background"
+@@ panel removed: panels[id=3] "Notes" @@
+-{
+-  "id": 3,
+-  "type": "text",
+-  "title": "Notes"
+-}
+`
+	if got := r.Unified(); got != want {
+		t.Errorf("Unified() got = %s, want %s", got, want)
+	}
+}
+
+func TestDashboards_Testdata(t *testing.T) {
+	data, err := os.ReadFile("../testdata/dashboard.yaml")
+	if err != nil {
+		t.Fatalf("failed to read json model, reason: %v", err)
+	}
+	old := testutil.MustUnmarshal[sdk.Dashboard](t, string(data))
+	new := testutil.MustUnmarshal[sdk.Dashboard](t, string(data))
+	new.ID, new.Version = 0, 42
+	delete(new.Extra, "iteration")
+	r, err := Dashboards(old, new)
+	if err != nil {
+		t.Fatalf("Dashboards() error = %v", err)
+	}
+	if !r.Equal() {
+		t.Errorf("Dashboards() got changes\n%s", r.Unified())
+	}
+}
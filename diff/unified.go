@@ -0,0 +1,92 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+var kindDescriptions = map[Kind]string{
+	DashboardChanged: "dashboard changed",
+	PanelAdded:       "panel added",
+	PanelRemoved:     "panel removed",
+	PanelMoved:       "panel moved",
+	PanelChanged:     "panel changed",
+	QueryAdded:       "query added",
+	QueryRemoved:     "query removed",
+	QueryChanged:     "query changed",
+	ThresholdChanged: "thresholds changed",
+	VariableAdded:    "variable added",
+	VariableRemoved:  "variable removed",
+	VariableChanged:  "variable changed",
+}
+
+// Unified renders the report in the style of a unified diff, e.g. for a pull request comment:
+//
+//	@@ panel changed: panels[id=2] "CPU" @@
+//	-options.legend.displayMode: "list"
+//	+options.legend.displayMode: "table"
+//
+// Added and removed objects are rendered as indented JSON.
+func (r *Report) Unified() string {
+	var b strings.Builder
+	for _, c := range r.Changes {
+		b.WriteString("@@ ")
+		b.WriteString(kindDescriptions[c.Kind])
+		if c.Path != "" {
+			b.WriteString(": ")
+			b.WriteString(c.Path)
+		}
+		if c.Title != "" {
+			b.WriteString(" ")
+			b.WriteString(strconv.Quote(c.Title))
+		}
+		b.WriteString(" @@\n")
+		for _, f := range c.Fields {
+			writeLines(&b, '-', f.Path, f.Old)
+			writeLines(&b, '+', f.Path, f.New)
+		}
+	}
+	return b.String()
+}
+
+func writeLines(b *strings.Builder, sign byte, path string, value json.RawMessage) {
+	if value == nil {
+		return
+	}
+	if path != "" {
+		b.WriteByte(sign)
+		b.WriteString(path)
+		b.WriteString(": ")
+		b.Write(value)
+		b.WriteByte('\n')
+		return
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, value, "", "  "); err != nil {
+		buf.Reset()
+		buf.Write(value)
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		b.WriteByte(sign)
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+}
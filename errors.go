@@ -0,0 +1,134 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by *APIError through errors.Is.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrRateLimited        = errors.New("rate limited")
+)
+
+// maxErrorBodyLen limits how much of a non-JSON body is quoted in Error().
+const maxErrorBodyLen = 256
+
+// APIError is returned by every Client method when Grafana answers with a non-2xx status code.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Message, MessageID, Status and TraceID are decoded from Grafana's JSON error body, if any.
+	Message   string
+	MessageID string
+	Status    string
+	TraceID   string
+	// Body is the raw response body.
+	Body []byte
+}
+
+// errorBody is the JSON error payload returned by Grafana.
+type errorBody struct {
+	Message   string `json:"message,omitempty"`
+	MessageID string `json:"messageId,omitempty"`
+	Status    string `json:"status,omitempty"`
+	TraceID   string `json:"traceID,omitempty"`
+}
+
+func newAPIError(method, url string, statusCode int, body []byte) *APIError {
+	e := &APIError{
+		Method:     method,
+		URL:        url,
+		StatusCode: statusCode,
+		Body:       body,
+	}
+	var eb errorBody
+	if json.Unmarshal(body, &eb) == nil {
+		e.Message = eb.Message
+		e.MessageID = eb.MessageID
+		e.Status = eb.Status
+		e.TraceID = eb.TraceID
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	switch {
+	case e.Message != "":
+		sb.WriteString(", reason: ")
+		sb.WriteString(e.Message)
+	case len(e.Body) > 0:
+		body := strings.TrimSpace(string(e.Body))
+		if len(body) > maxErrorBodyLen {
+			body = body[:maxErrorBodyLen] + "..."
+		}
+		sb.WriteString(", body: ")
+		sb.WriteString(body)
+	}
+	if e.TraceID != "" {
+		sb.WriteString(", traceID: ")
+		sb.WriteString(e.TraceID)
+	}
+	return sb.String()
+}
+
+// Is reports whether the status code of e corresponds to the given sentinel error.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// IsNotFound reports whether err is or wraps an APIError with status 404.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict reports whether err is or wraps an APIError with status 409.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsPreconditionFailed reports whether err is or wraps an APIError with status 412,
+// which Grafana uses for dashboard version mismatches.
+func IsPreconditionFailed(err error) bool {
+	return errors.Is(err, ErrPreconditionFailed)
+}
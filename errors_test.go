@@ -0,0 +1,113 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		body        string
+		wantErr     error
+		wantMessage string
+		wantTraceID string
+	}{
+		{
+			name:        "Dashboard not found",
+			statusCode:  http.StatusNotFound,
+			body:        `{"message":"Dashboard not found","traceID":"abc"}`,
+			wantErr:     ErrNotFound,
+			wantMessage: "Dashboard not found",
+			wantTraceID: "abc",
+		},
+		{
+			name:        "Datasource already exists",
+			statusCode:  http.StatusConflict,
+			body:        `{"message":"data source with the same name already exists"}`,
+			wantErr:     ErrConflict,
+			wantMessage: "data source with the same name already exists",
+		},
+		{
+			name:        "Dashboard version mismatch",
+			statusCode:  http.StatusPreconditionFailed,
+			body:        `{"message":"The dashboard has been changed by someone else","status":"version-mismatch"}`,
+			wantErr:     ErrPreconditionFailed,
+			wantMessage: "The dashboard has been changed by someone else",
+		},
+		{
+			name:        "Invalid credentials",
+			statusCode:  http.StatusUnauthorized,
+			body:        `{"message":"Invalid username or password","messageId":"password-auth.failed"}`,
+			wantErr:     ErrUnauthorized,
+			wantMessage: "Invalid username or password",
+		},
+		{
+			name:       "Non JSON bad gateway",
+			statusCode: http.StatusBadGateway,
+			body:       `<html><body>502 Bad Gateway</body></html>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c, err := NewClient(srv.URL, WithAuth(validAuth))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			_, err = c.DeleteDashboardByUID(context.TODO(), "uid")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("DeleteDashboardByUID() error = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.statusCode {
+				t.Errorf("StatusCode got = %v, want %v", apiErr.StatusCode, tt.statusCode)
+			}
+			if apiErr.Method != http.MethodDelete {
+				t.Errorf("Method got = %v, want %v", apiErr.Method, http.MethodDelete)
+			}
+			if string(apiErr.Body) != tt.body {
+				t.Errorf("Body got = %s, want %s", apiErr.Body, tt.body)
+			}
+			if apiErr.Message != tt.wantMessage {
+				t.Errorf("Message got = %v, want %v", apiErr.Message, tt.wantMessage)
+			}
+			if apiErr.TraceID != tt.wantTraceID {
+				t.Errorf("TraceID got = %v, want %v", apiErr.TraceID, tt.wantTraceID)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.wantErr)
+			}
+			for _, sentinel := range []error{ErrNotFound, ErrConflict, ErrPreconditionFailed, ErrUnauthorized, ErrForbidden, ErrRateLimited} {
+				if sentinel != tt.wantErr && errors.Is(err, sentinel) {
+					t.Errorf("errors.Is(%v, %v) = true, want false", err, sentinel)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// folderPageSize is the page size used by ListFolders.
+const folderPageSize = 1000
+
+// Folder as described in the doc
+// https://grafana.com/docs/grafana/latest/developers/http_api/folder/
+type Folder struct {
+	ID        int    `json:"id,omitempty"`
+	UID       string `json:"uid,omitempty"`
+	Title     string `json:"title"`
+	URL       string `json:"url,omitempty"`
+	ParentUID string `json:"parentUid,omitempty"`
+	// Parents lists the ancestors of a nested folder, starting at the root.
+	Parents   []Folder  `json:"parents,omitempty"`
+	HasACL    bool      `json:"hasAcl,omitempty"`
+	CanSave   bool      `json:"canSave,omitempty"`
+	CanEdit   bool      `json:"canEdit,omitempty"`
+	CanAdmin  bool      `json:"canAdmin,omitempty"`
+	CanDelete bool      `json:"canDelete,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	Created   time.Time `json:"created,omitzero"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	Updated   time.Time `json:"updated,omitzero"`
+	Version   int       `json:"version,omitempty"`
+}
+
+type createFolderRequest struct {
+	UID       string `json:"uid,omitempty"`
+	Title     string `json:"title"`
+	ParentUID string `json:"parentUid,omitempty"`
+}
+
+type updateFolderRequest struct {
+	Title     string `json:"title"`
+	Version   int    `json:"version,omitempty"`
+	Overwrite bool   `json:"overwrite,omitempty"`
+}
+
+type moveFolderRequest struct {
+	ParentUID string `json:"parentUid"`
+}
+
+// CreateFolder creates a folder. If f.UID is empty Grafana generates one.
+// f.ParentUID creates the folder inside another folder when nested folders are enabled.
+// It reflects POST /api/folders API call.
+func (c *Client) CreateFolder(ctx context.Context, f Folder) (*Folder, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/folders")
+	resp, err := c.do(ctx, http.MethodPost, u.String(), createFolderRequest{
+		UID:       f.UID,
+		Title:     f.Title,
+		ParentUID: f.ParentUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	folder := &Folder{}
+	if err = decodeResponse(resp, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// GetFolderByUID returns the folder with the given uid.
+// It reflects GET /api/folders/:uid API call.
+func (c *Client) GetFolderByUID(ctx context.Context, uid string) (*Folder, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/folders", uid)
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	folder := &Folder{}
+	if err = decodeResponse(resp, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// ListFolders returns the folders directly inside the folder with the given uid,
+// or the top level folders if parentUID is empty.
+// It reflects GET /api/folders API call.
+func (c *Client) ListFolders(ctx context.Context, parentUID string) ([]Folder, error) {
+	var folders []Folder
+	for page := 1; ; page++ {
+		u, _ := url.Parse(c.baseURL)
+		u.Path = path.Join(u.Path, "api/folders")
+		q := url.Values{}
+		q.Set("limit", strconv.Itoa(folderPageSize))
+		q.Set("page", strconv.Itoa(page))
+		if parentUID != "" {
+			q.Set("parentUid", parentUID)
+		}
+		u.RawQuery = q.Encode()
+		resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		var list []Folder
+		if err = decodeResponse(resp, &list); err != nil {
+			return nil, err
+		}
+		folders = append(folders, list...)
+		if len(list) < folderPageSize {
+			return folders, nil
+		}
+	}
+}
+
+// UpdateFolder changes the title of the folder identified by f.UID. f.Version must match the
+// current version of the folder unless overwrite is set.
+// It reflects PUT /api/folders/:uid API call.
+func (c *Client) UpdateFolder(ctx context.Context, f Folder, overwrite bool) (*Folder, error) {
+	if f.UID == "" {
+		return nil, errors.New("failed to update folder, reason: missing folder uid")
+	}
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/folders", f.UID)
+	resp, err := c.do(ctx, http.MethodPut, u.String(), updateFolderRequest{
+		Title:     f.Title,
+		Version:   f.Version,
+		Overwrite: overwrite,
+	})
+	if err != nil {
+		return nil, err
+	}
+	folder := &Folder{}
+	if err = decodeResponse(resp, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// DeleteFolder deletes the folder with the given uid along with its dashboards.
+// It reflects DELETE /api/folders/:uid API call.
+func (c *Client) DeleteFolder(ctx context.Context, uid string) (*GrafanaResponse, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/folders", uid)
+	resp, err := c.do(ctx, http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
+
+// MoveFolder moves the folder with the given uid into the folder with uid parentUID,
+// or to the top level if parentUID is empty. It requires nested folders to be enabled.
+// It reflects POST /api/folders/:uid/move API call.
+func (c *Client) MoveFolder(ctx context.Context, uid, parentUID string) (*Folder, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/folders", uid, "move")
+	resp, err := c.do(ctx, http.MethodPost, u.String(), moveFolderRequest{ParentUID: parentUID})
+	if err != nil {
+		return nil, err
+	}
+	folder := &Folder{}
+	if err = decodeResponse(resp, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// EnsureFolderPath makes sure the nested folders named by the '/' separated folderPath exist,
+// e.g. "Databases/Postgres/Prod", creating missing ones, and returns the uid of the last folder.
+// Folders are matched by title.
+func (c *Client) EnsureFolderPath(ctx context.Context, folderPath string) (string, error) {
+	var parentUID string
+	var found bool
+	for _, title := range strings.Split(folderPath, "/") {
+		title = strings.TrimSpace(title)
+		if title == "" {
+			continue
+		}
+		found = true
+		folders, err := c.ListFolders(ctx, parentUID)
+		if err != nil {
+			return "", err
+		}
+		uid := ""
+		for _, f := range folders {
+			if f.Title == title {
+				uid = f.UID
+				break
+			}
+		}
+		if uid == "" {
+			f, err := c.CreateFolder(ctx, Folder{Title: title, ParentUID: parentUID})
+			if err != nil {
+				return "", err
+			}
+			uid = f.UID
+		}
+		parentUID = uid
+	}
+	if !found {
+		return "", errors.New("empty folder path")
+	}
+	return parentUID, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newFolderServer returns a server keeping nested folders in memory.
+func newFolderServer(t *testing.T, folders map[string]*Folder) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/folders", func(w http.ResponseWriter, r *http.Request) {
+		list := []Folder{}
+		for _, f := range folders {
+			if f.ParentUID == r.URL.Query().Get("parentUid") {
+				list = append(list, *f)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("POST /api/folders", func(w http.ResponseWriter, r *http.Request) {
+		var req createFolderRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f := &Folder{ID: len(folders) + 1, UID: fmt.Sprintf("f%d", len(folders)+1), Title: req.Title, ParentUID: req.ParentUID, Version: 1}
+		folders[f.UID] = f
+		_ = json.NewEncoder(w).Encode(f)
+	})
+	mux.HandleFunc("GET /api/folders/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		f, ok := folders[r.PathValue("uid")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"folder not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f)
+	})
+	mux.HandleFunc("PUT /api/folders/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		var req updateFolderRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f := folders[r.PathValue("uid")]
+		if !req.Overwrite && req.Version != f.Version {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = w.Write([]byte(`{"message":"the folder has been changed by someone else","status":"version-mismatch"}`))
+			return
+		}
+		f.Title = req.Title
+		f.Version++
+		_ = json.NewEncoder(w).Encode(f)
+	})
+	mux.HandleFunc("POST /api/folders/{uid}/move", func(w http.ResponseWriter, r *http.Request) {
+		var req moveFolderRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f := folders[r.PathValue("uid")]
+		f.ParentUID = req.ParentUID
+		_ = json.NewEncoder(w).Encode(f)
+	})
+	mux.HandleFunc("DELETE /api/folders/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		delete(folders, r.PathValue("uid"))
+		_, _ = w.Write([]byte(`{"message":"Folder deleted"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_EnsureFolderPath(t *testing.T) {
+	folders := map[string]*Folder{
+		"db": {ID: 1, UID: "db", Title: "Databases"},
+	}
+	srv := newFolderServer(t, folders)
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantFolders int
+		wantErr     bool
+	}{
+		{name: "Existing folder", path: "Databases", wantFolders: 1},
+		{name: "Create missing segments", path: "Databases/Postgres/Prod", wantFolders: 3},
+		{name: "Existing nested path", path: "/Databases/ Postgres /Prod/", wantFolders: 3},
+		{name: "Empty path", path: "/", wantFolders: 3, wantErr: true},
+	}
+	leafs := map[string]string{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, err := c.EnsureFolderPath(context.TODO(), tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnsureFolderPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(folders) != tt.wantFolders {
+				t.Errorf("folders got = %v, want %v", len(folders), tt.wantFolders)
+			}
+			if tt.wantErr {
+				return
+			}
+			leafs[tt.name] = uid
+			if f := folders[uid]; f == nil {
+				t.Errorf("EnsureFolderPath() returned unknown uid %v", uid)
+			}
+		})
+	}
+	if leafs["Create missing segments"] != leafs["Existing nested path"] {
+		t.Errorf("EnsureFolderPath() is not idempotent, got %v", leafs)
+	}
+	leaf := folders[leafs["Create missing segments"]]
+	parent := folders[leaf.ParentUID]
+	if leaf.Title != "Prod" || parent.Title != "Postgres" || parent.ParentUID != "db" {
+		t.Errorf("EnsureFolderPath() created leaf = %+v, parent = %+v", leaf, parent)
+	}
+}
+
+func TestClient_Folders(t *testing.T) {
+	folders := map[string]*Folder{}
+	srv := newFolderServer(t, folders)
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	ctx := context.TODO()
+
+	parent, err := c.CreateFolder(ctx, Folder{Title: "Databases"})
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	child, err := c.CreateFolder(ctx, Folder{Title: "Redis"})
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	if child, err = c.MoveFolder(ctx, child.UID, parent.UID); err != nil || child.ParentUID != parent.UID {
+		t.Fatalf("MoveFolder() got = %+v, error = %v", child, err)
+	}
+	list, err := c.ListFolders(ctx, parent.UID)
+	if err != nil || len(list) != 1 || list[0].UID != child.UID {
+		t.Errorf("ListFolders() got = %+v, error = %v", list, err)
+	}
+
+	stale := *child
+	if child, err = c.UpdateFolder(ctx, Folder{UID: child.UID, Title: "Cache", Version: child.Version}, false); err != nil || child.Title != "Cache" {
+		t.Fatalf("UpdateFolder() got = %+v, error = %v", child, err)
+	}
+	if _, err = c.UpdateFolder(ctx, stale, false); !IsPreconditionFailed(err) {
+		t.Errorf("UpdateFolder() with stale version error = %v, want precondition failed", err)
+	}
+	if _, err = c.UpdateFolder(ctx, stale, true); err != nil {
+		t.Errorf("UpdateFolder() with overwrite error = %v", err)
+	}
+
+	if _, err = c.DeleteFolder(ctx, child.UID); err != nil {
+		t.Fatalf("DeleteFolder() error = %v", err)
+	}
+	if _, err = c.GetFolderByUID(ctx, child.UID); !IsNotFound(err) {
+		t.Errorf("GetFolderByUID() error = %v, want not found", err)
+	}
+}
+
+func TestGrafanaDashboard_FolderUid(t *testing.T) {
+	data, err := json.Marshal(GrafanaDashboard{FolderUid: "db"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"folderUid":"db"}` {
+		t.Errorf("Marshal() got = %s, want {\"folderUid\":\"db\"}", data)
+	}
+}
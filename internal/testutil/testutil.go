@@ -0,0 +1,59 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutil holds helpers shared by the tests of this module.
+package testutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// JSONEqual reports whether a and b encode the same JSON value. It fails t if either is invalid.
+func JSONEqual(t testing.TB, a, b []byte) bool {
+	t.Helper()
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("invalid json %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("invalid json %s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// MustUnmarshal decodes the JSON data into a new T. It fails t if data is invalid.
+func MustUnmarshal[T any](t testing.TB, data string) *T {
+	t.Helper()
+	v := new(T)
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		t.Fatalf("invalid json %s: %v", data, err)
+	}
+	return v
+}
+
+// AssertJSON fails t unless got encodes the same JSON value as want.
+func AssertJSON(t testing.TB, got any, want string) {
+	t.Helper()
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if !JSONEqual(t, data, []byte(want)) {
+		t.Errorf("got = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interpolate
+
+import (
+	"fmt"
+	"maps"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+// Query is a query of a panel with its template variables expanded.
+type Query struct {
+	// Path locates the target in the dashboard, e.g. panels[2].targets[0].
+	Path string
+	// PanelID and PanelTitle identify the panel. The title is expanded, so repeated panels can be
+	// told apart.
+	PanelID    int
+	PanelTitle string
+	// Datasource is the expanded datasource of the query, and DatasourceType its plugin type if it
+	// is known from the reference or from the datasource variable it uses.
+	Datasource     *sdk.DataSourceRef
+	DatasourceType string
+	Target         sdk.Target
+}
+
+// Dashboard returns the queries the panels of the dashboard send for the selected variable values.
+// Repeated panels and rows yield the queries of every repetition, and hidden queries are skipped.
+func Dashboard(d *sdk.Dashboard, opts Options) ([]Query, error) {
+	ip, err := New(d, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ip.Queries(), nil
+}
+
+// Queries returns the queries of the dashboard, see Dashboard.
+func (ip *Interpolator) Queries() []Query {
+	var out []Query
+	// rowScopes are the repetitions of the current row. Panels that follow an expanded row belong to it.
+	rowScopes := []scope{nil}
+	for i := range ip.dashboard.Panels {
+		p := &ip.dashboard.Panels[i]
+		path := fmt.Sprintf("panels[%d]", i)
+		if !p.IsRow() {
+			for _, sc := range rowScopes {
+				out = append(out, ip.panelQueries(p, path, sc)...)
+			}
+			continue
+		}
+		rowScopes = ip.repetitions(p, nil)
+		if !p.Collapsed {
+			continue
+		}
+		for j := range p.Panels {
+			for _, sc := range rowScopes {
+				out = append(out, ip.panelQueries(&p.Panels[j], fmt.Sprintf("%s.panels[%d]", path, j), sc)...)
+			}
+		}
+	}
+	return out
+}
+
+// repetitions returns the scopes of the repetitions of a repeated panel or row, one per selected
+// value of the repeat variable, or the parent scope if it is not repeated.
+func (ip *Interpolator) repetitions(p *sdk.Panel, parent scope) []scope {
+	val, ok := ip.values[p.Repeat]
+	if p.Repeat == "" || !ok || len(val.values) == 0 {
+		return []scope{parent}
+	}
+	if val.customAll {
+		// Grafana repeats for every option even if All has a custom value.
+		val.values = allValues(ip.variables[p.Repeat], ip.opts)
+	}
+	out := make([]scope, 0, len(val.values))
+	for i, s := range val.values {
+		sc := scope{}
+		maps.Copy(sc, parent)
+		text := s
+		if i < len(val.texts) && len(val.texts) == len(val.values) {
+			text = val.texts[i]
+		}
+		sc[p.Repeat] = value{name: p.Repeat, values: []string{s}, texts: []string{text}}
+		out = append(out, sc)
+	}
+	return out
+}
+
+func (ip *Interpolator) panelQueries(p *sdk.Panel, path string, parent scope) []Query {
+	var out []Query
+	for _, sc := range ip.repetitions(p, parent) {
+		title := ip.replace(p.Title, "", sc)
+		for i, t := range p.Targets {
+			if t.Hide {
+				continue
+			}
+			ref := t.Datasource
+			if ref == nil || ref.UID == "-- Mixed --" {
+				ref = p.Datasource
+			}
+			ref, typ := ip.datasource(ref, sc)
+			t.Datasource = ref
+			t.Expr = ip.replace(t.Expr, typ, sc)
+			t.Query = ip.replace(t.Query, typ, sc)
+			t.RawSQL = ip.replace(t.RawSQL, typ, sc)
+			t.LegendFormat = ip.replace(t.LegendFormat, typ, sc)
+			t.Interval = ip.replace(t.Interval, "", sc)
+			out = append(out, Query{
+				Path:           fmt.Sprintf("%s.targets[%d]", path, i),
+				PanelID:        p.ID,
+				PanelTitle:     title,
+				Datasource:     ref,
+				DatasourceType: typ,
+				Target:         t,
+			})
+		}
+	}
+	return out
+}
+
+// datasource expands the datasource reference and returns the plugin type of the datasource.
+func (ip *Interpolator) datasource(ref *sdk.DataSourceRef, sc scope) (*sdk.DataSourceRef, string) {
+	if ref == nil {
+		return nil, ""
+	}
+	typ := ref.Type
+	if typ == "" {
+		for _, s := range []string{ref.UID, ref.Name} {
+			m := variableRef.FindStringSubmatch(s)
+			if m == nil {
+				continue
+			}
+			if v, ok := ip.variables[m[1]+m[2]+m[4]]; ok && v.Type == "datasource" {
+				typ, _ = v.Query.(string)
+			}
+		}
+	}
+	return &sdk.DataSourceRef{
+		Type: typ,
+		UID:  ip.replace(ref.UID, "", sc),
+		Name: ip.replace(ref.Name, "", sc),
+	}, typ
+}
@@ -0,0 +1,106 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interpolate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+func TestDashboard(t *testing.T) {
+	d := &sdk.Dashboard{}
+	if err := json.Unmarshal([]byte(testDashboard), d); err != nil {
+		t.Fatalf("invalid dashboard: %v", err)
+	}
+	got, err := Dashboard(d, Options{Interval: time.Minute})
+	if err != nil {
+		t.Fatalf("Dashboard() error = %v", err)
+	}
+
+	prometheus := &sdk.DataSourceRef{Type: "prometheus", UID: "P1"}
+	want := []Query{
+		{
+			Path: "panels[0].targets[0]", PanelID: 1, PanelTitle: "Pods in {a,b}",
+			Datasource: prometheus, DatasourceType: "prometheus",
+			Target: sdk.Target{RefID: "A", Datasource: prometheus, Expr: `up{namespace=~"(a|b)", host=~"(h1\\.example\\.com|h2)"}[1m]`, LegendFormat: "{{pod}}"},
+		},
+		{
+			Path: "panels[1].targets[0]", PanelID: 2, PanelTitle: "Pods of Alpha",
+			Datasource: prometheus, DatasourceType: "prometheus",
+			Target: sdk.Target{RefID: "A", Datasource: prometheus, Expr: `count(up{namespace="a"})`},
+		},
+		{
+			Path: "panels[1].targets[0]", PanelID: 2, PanelTitle: "Pods of Beta",
+			Datasource: prometheus, DatasourceType: "prometheus",
+			Target: sdk.Target{RefID: "A", Datasource: prometheus, Expr: `count(up{namespace="b"})`},
+		},
+		{
+			Path: "panels[2].panels[0].targets[0]", PanelID: 4, PanelTitle: "Rows",
+			Datasource:     &sdk.DataSourceRef{Type: "grafana-postgresql-datasource", UID: "PG"},
+			DatasourceType: "grafana-postgresql-datasource",
+			Target: sdk.Target{
+				RefID:      "A",
+				Datasource: &sdk.DataSourceRef{Type: "grafana-postgresql-datasource", UID: "PG"},
+				RawSQL:     "SELECT * FROM pods WHERE ns IN ('a','b') AND $__timeFilter(time) AND env ~ '.*'",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		wantJSON, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("Dashboard() got = %s, want %s", gotJSON, wantJSON)
+	}
+}
+
+func TestDashboard_RepeatedRow(t *testing.T) {
+	d := &sdk.Dashboard{}
+	data := `{
+  "templating": {"list": [
+    {"name": "region", "type": "custom", "multi": true, "current": {"text": ["eu", "us"], "value": ["eu", "us"]}},
+    {"name": "job", "type": "custom", "multi": true, "current": {"text": ["api", "db"], "value": ["api", "db"]}}
+  ]},
+  "panels": [
+    {"id": 1, "type": "row", "title": "$region", "repeat": "region"},
+    {"id": 2, "type": "stat", "title": "$job in $region", "repeat": "job", "datasource": {"type": "loki", "uid": "L"},
+     "targets": [{"refId": "A", "expr": "{region=\"$region\", job=~\"$job\"}"}]}
+  ]
+}`
+	if err := json.Unmarshal([]byte(data), d); err != nil {
+		t.Fatalf("invalid dashboard: %v", err)
+	}
+	queries, err := Dashboard(d, Options{Values: map[string][]string{"job": {"api", "db"}}})
+	if err != nil {
+		t.Fatalf("Dashboard() error = %v", err)
+	}
+	var got []string
+	for _, q := range queries {
+		got = append(got, q.PanelTitle+": "+q.Target.Expr)
+	}
+	want := []string{
+		`api in eu: {region="eu", job=~"api"}`,
+		`db in eu: {region="eu", job=~"db"}`,
+		`api in us: {region="us", job=~"api"}`,
+		`db in us: {region="us", job=~"db"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Dashboard() got = %q, want %q", got, want)
+	}
+}
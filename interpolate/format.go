@@ -0,0 +1,265 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interpolate
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Names of the formats of the ${var:format} syntax.
+const (
+	FormatCSV           = "csv"
+	FormatDistributed   = "distributed"
+	FormatDoubleQuote   = "doublequote"
+	FormatGlob          = "glob"
+	FormatHTML          = "html"
+	FormatJSON          = "json"
+	FormatLucene        = "lucene"
+	FormatPercentEncode = "percentencode"
+	FormatPipe          = "pipe"
+	FormatQueryParam    = "queryparam"
+	FormatRaw           = "raw"
+	FormatRegex         = "regex"
+	FormatSingleQuote   = "singlequote"
+	FormatSQLString     = "sqlstring"
+	FormatText          = "text"
+)
+
+// value is the value of a variable as seen by a format. It is a list if the variable is multi-value,
+// even if a single value is selected, like in Grafana.
+type value struct {
+	name   string
+	values []string
+	texts  []string
+	isList bool
+	// multi is set for variables that allow selecting several values or All.
+	multi bool
+	// customAll is set if All is selected and the variable has a custom all value, which is the only value.
+	customAll bool
+}
+
+func (v value) first() string {
+	if len(v.values) == 0 {
+		return ""
+	}
+	return v.values[0]
+}
+
+// each formats the single value, or every value of a list joined by sep.
+func (v value) each(f func(s string) string, sep string) string {
+	if !v.isList {
+		return f(v.first())
+	}
+	out := make([]string, len(v.values))
+	for i, s := range v.values {
+		out[i] = f(s)
+	}
+	return strings.Join(out, sep)
+}
+
+func identity(s string) string {
+	return s
+}
+
+type formatter func(v value) string
+
+var formats = map[string]formatter{
+	FormatCSV: func(v value) string {
+		return v.each(identity, ",")
+	},
+	FormatDistributed: func(v value) string {
+		if !v.isList {
+			return v.first()
+		}
+		out := make([]string, len(v.values))
+		for i, s := range v.values {
+			if i > 0 {
+				s = v.name + "=" + s
+			}
+			out[i] = s
+		}
+		return strings.Join(out, ",")
+	},
+	FormatDoubleQuote: func(v value) string {
+		return v.each(func(s string) string {
+			return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+		}, ",")
+	},
+	FormatGlob: func(v value) string {
+		if v.isList && len(v.values) > 1 {
+			return "{" + strings.Join(v.values, ",") + "}"
+		}
+		return v.first()
+	},
+	FormatHTML: func(v value) string {
+		return v.each(html.EscapeString, ", ")
+	},
+	FormatJSON: func(v value) string {
+		var data []byte
+		if v.isList {
+			data, _ = json.Marshal(append([]string{}, v.values...))
+		} else {
+			data, _ = json.Marshal(v.first())
+		}
+		return string(data)
+	},
+	FormatLucene: func(v value) string {
+		if !v.isList {
+			return luceneEscape(v.first())
+		}
+		if len(v.values) == 0 {
+			return "__empty__"
+		}
+		return "(" + v.each(func(s string) string {
+			return `"` + luceneEscape(s) + `"`
+		}, " OR ") + ")"
+	},
+	FormatPercentEncode: func(v value) string {
+		if !v.isList {
+			return encodeURIComponent(v.first())
+		}
+		return encodeURIComponent("{" + strings.Join(v.values, ",") + "}")
+	},
+	FormatPipe: func(v value) string {
+		return v.each(identity, "|")
+	},
+	FormatQueryParam: func(v value) string {
+		return v.each(func(s string) string {
+			return "var-" + url.QueryEscape(v.name) + "=" + url.QueryEscape(s)
+		}, "&")
+	},
+	FormatRaw: func(v value) string {
+		return v.each(identity, ",")
+	},
+	FormatRegex: func(v value) string {
+		if !v.isList {
+			return regexEscape(v.first())
+		}
+		if len(v.values) == 1 {
+			return regexEscape(v.values[0])
+		}
+		return "(" + v.each(regexEscape, "|") + ")"
+	},
+	FormatSingleQuote: func(v value) string {
+		return v.each(func(s string) string {
+			return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+		}, ",")
+	},
+	FormatSQLString: func(v value) string {
+		return v.each(quoteLiteral, ",")
+	},
+	FormatText: func(v value) string {
+		return strings.Join(v.texts, " + ")
+	},
+}
+
+// datasourceFormats are the formats the datasources of the given plugin types apply to variables
+// referenced without format. Other datasources use the glob format.
+var datasourceFormats = map[string]formatter{
+	"prometheus": func(v value) string {
+		return regexQueryExpr(v, prometheusRegularEscape, prometheusRegexEscape, true)
+	},
+	"loki": func(v value) string {
+		return regexQueryExpr(v, lokiRegularEscape, lokiRegexEscape, false)
+	},
+	"grafana-postgresql-datasource": sqlQueryExpr,
+	"postgres":                      sqlQueryExpr,
+	"mysql":                         sqlQueryExpr,
+	"elasticsearch":                 formats[FormatLucene],
+}
+
+// regexQueryExpr formats variables for the PromQL and LogQL regex matchers: values of multi-value
+// variables are regex escaped and joined by |, single values are only escaped for string literals.
+func regexQueryExpr(v value, regular, special func(string) string, group bool) string {
+	if !v.multi {
+		return v.each(regular, ",")
+	}
+	if !v.isList || len(v.values) == 1 {
+		return special(v.first())
+	}
+	s := v.each(special, "|")
+	if group {
+		s = "(" + s + ")"
+	}
+	return s
+}
+
+func sqlQueryExpr(v value) string {
+	if v.isList {
+		return v.each(quoteLiteral, ",")
+	}
+	if v.multi {
+		return quoteLiteral(v.first())
+	}
+	return strings.ReplaceAll(v.first(), "'", "''")
+}
+
+var (
+	luceneSpecialChars  = regexp.MustCompile(`[!*+\-=<>\s&|()\[\]{}^~?:\\/"]`)
+	regexSpecialChars   = regexp.MustCompile(`[\\^$*+?.()|\[\]{}/]`)
+	promQLSpecialChars  = regexp.MustCompile(`[$^*{}\[\]'+?.()|]`)
+	logQLSpecialChars   = regexp.MustCompile(`[$^*{}\[\]+?.()|]`)
+	backslashEscaper    = strings.NewReplacer(`\`, `\\`)
+	backslash2Escaper   = strings.NewReplacer(`\`, `\\\\`)
+	singleQuoteEscaper  = strings.NewReplacer("'", `\\'`)
+	uriComponentUnsafe  = regexp.MustCompile(`[^A-Za-z0-9\-_.!~*'()]`)
+	doubleEscapeMatches = `\\$0`
+)
+
+func luceneEscape(s string) string {
+	return luceneSpecialChars.ReplaceAllString(s, `\$0`)
+}
+
+func regexEscape(s string) string {
+	return regexSpecialChars.ReplaceAllString(s, `\$0`)
+}
+
+func prometheusRegularEscape(s string) string {
+	return singleQuoteEscaper.Replace(backslashEscaper.Replace(s))
+}
+
+func prometheusRegexEscape(s string) string {
+	return promQLSpecialChars.ReplaceAllString(backslash2Escaper.Replace(s), doubleEscapeMatches)
+}
+
+func lokiRegularEscape(s string) string {
+	return singleQuoteEscaper.Replace(s)
+}
+
+func lokiRegexEscape(s string) string {
+	return lokiRegularEscape(logQLSpecialChars.ReplaceAllString(backslash2Escaper.Replace(s), doubleEscapeMatches))
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// encodeURIComponent escapes s like the JavaScript function of the same name.
+func encodeURIComponent(s string) string {
+	return uriComponentUnsafe.ReplaceAllStringFunc(s, func(c string) string {
+		var b strings.Builder
+		for i := 0; i < len(c); i++ {
+			fmt.Fprintf(&b, "%%%02X", c[i])
+		}
+		return b.String()
+	})
+}
@@ -0,0 +1,259 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package interpolate expands the template variables of a dashboard offline, the way Grafana does
+// before it sends the queries of a panel to the datasource. It supports the $var, ${var},
+// ${var:format} and [[var]] syntaxes, multi-value variables, All, repeated panels and rows, and the
+// $__interval, $__rate_interval, $__range, $__from and $__to built-in variables.
+package interpolate
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+// All is the value selecting all values of a variable with includeAll.
+const All = "$__all"
+
+const (
+	allText               = "All"
+	defaultScrapeInterval = 15 * time.Second
+)
+
+// Options configures the interpolation.
+type Options struct {
+	// Values selects the values of variables by name, overriding the current value saved in the
+	// dashboard. Select All with []string{All}.
+	Values map[string][]string
+	// Choices are the values of query variables that would be returned by their datasource. They
+	// are used to expand All when the dashboard has no saved options for the variable.
+	Choices map[string][]string
+	// From and To is the time range of the dashboard, used for $__from, $__to and $__range.
+	From, To time.Time
+	// Interval is the value of $__interval, used for $__rate_interval as well.
+	Interval time.Duration
+	// ScrapeInterval is the scrape interval of the Prometheus datasources, 15s by default.
+	ScrapeInterval time.Duration
+}
+
+// Interpolator expands the variables of a dashboard. Built-in variables are left unexpanded if
+// Options lacks the values they depend on, and so are unknown variables, like Grafana does.
+type Interpolator struct {
+	dashboard *sdk.Dashboard
+	opts      Options
+	variables map[string]*sdk.Variable
+	values    map[string]value
+	builtins  map[string]string
+}
+
+// scope holds the values of the variables a panel is repeated for.
+type scope map[string]value
+
+// New returns an Interpolator for the dashboard. It fails if Options selects values of a variable the
+// dashboard does not define.
+func New(d *sdk.Dashboard, opts Options) (*Interpolator, error) {
+	ip := &Interpolator{
+		dashboard: d,
+		opts:      opts,
+		variables: map[string]*sdk.Variable{},
+		values:    map[string]value{},
+		builtins:  builtins(opts),
+	}
+	if d.Templating != nil {
+		for i := range d.Templating.List {
+			v := &d.Templating.List[i]
+			ip.variables[v.Name] = v
+		}
+	}
+	for name := range opts.Values {
+		if _, ok := ip.variables[name]; !ok {
+			return nil, fmt.Errorf("failed to select value of variable %q, reason: the dashboard does not define it", name)
+		}
+	}
+	for name, v := range ip.variables {
+		ip.values[name] = selectedValue(v, opts)
+	}
+	return ip, nil
+}
+
+func builtins(opts Options) map[string]string {
+	out := map[string]string{}
+	if opts.Interval > 0 {
+		scrape := opts.ScrapeInterval
+		if scrape <= 0 {
+			scrape = defaultScrapeInterval
+		}
+		out["__interval"] = formatInterval(opts.Interval)
+		out["__interval_ms"] = strconv.FormatInt(opts.Interval.Milliseconds(), 10)
+		out["__rate_interval"] = formatInterval(max(opts.Interval+scrape, 4*scrape))
+	}
+	if !opts.From.IsZero() && !opts.To.IsZero() {
+		r := opts.To.Sub(opts.From)
+		out["__from"] = strconv.FormatInt(opts.From.UnixMilli(), 10)
+		out["__to"] = strconv.FormatInt(opts.To.UnixMilli(), 10)
+		out["__range"] = strconv.FormatInt(int64(r.Round(time.Second)/time.Second), 10) + "s"
+		out["__range_s"] = strconv.FormatInt(int64(r.Round(time.Second)/time.Second), 10)
+		out["__range_ms"] = strconv.FormatInt(r.Milliseconds(), 10)
+	}
+	return out
+}
+
+// formatInterval formats d in its largest whole unit, e.g. 90s as 1m, like Grafana.
+func formatInterval(d time.Duration) string {
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"y", 365 * 24 * time.Hour},
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+	for _, u := range units {
+		if n := d / u.size; n > 0 {
+			return strconv.FormatInt(int64(n), 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
+
+// selectedValue returns the value of the variable selected in opts or saved in the dashboard,
+// with All expanded.
+func selectedValue(v *sdk.Variable, opts Options) value {
+	if v.Type == "constant" {
+		q, _ := v.Query.(string)
+		return value{name: v.Name, values: []string{q}, texts: []string{q}}
+	}
+
+	val := value{name: v.Name, isList: v.Multi, multi: v.Multi || v.IncludeAll}
+	if selected, ok := opts.Values[v.Name]; ok {
+		val.values = selected
+		val.isList = v.Multi || len(selected) > 1
+		for _, s := range selected {
+			val.texts = append(val.texts, optionText(v, s))
+		}
+	} else if v.Current != nil {
+		val.values = v.Current.Value.Values
+		val.texts = v.Current.Text.Values
+		val.isList = v.Current.Value.IsList
+	}
+	if len(val.values) == 1 && val.values[0] == All {
+		val.texts = []string{allText}
+		if v.AllValue != "" {
+			val.values = []string{v.AllValue}
+			val.isList = false
+			val.customAll = true
+			return val
+		}
+		val.values = allValues(v, opts)
+		val.isList = true
+	}
+	return val
+}
+
+func allValues(v *sdk.Variable, opts Options) []string {
+	if choices, ok := opts.Choices[v.Name]; ok {
+		return choices
+	}
+	out := []string{}
+	for _, o := range v.Options {
+		if s := o.Value.String(); s != All {
+			out = append(out, s)
+		}
+	}
+	if q, ok := v.Query.(string); ok && len(out) == 0 && v.Type == "custom" {
+		for _, s := range strings.Split(q, ",") {
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
+
+func optionText(v *sdk.Variable, s string) string {
+	if s == All {
+		return allText
+	}
+	for _, o := range v.Options {
+		if o.Value.String() == s {
+			return o.Text.String()
+		}
+	}
+	return s
+}
+
+// variableRef is the regular expression Grafana uses to find variable references.
+var variableRef = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.([^:^\}]+))?(?::([^\}]+))?\}`)
+
+// Replace expands the variables referenced in s the way the datasource of the given plugin type does,
+// e.g. prometheus or postgres, applying its escaping to variables referenced without format.
+// Datasources of other types and an empty type use the glob format.
+func (ip *Interpolator) Replace(s, datasourceType string) string {
+	return ip.replace(s, datasourceType, nil)
+}
+
+func (ip *Interpolator) replace(s, datasourceType string, sc scope) string {
+	return ip.expand(s, datasourceType, sc, nil)
+}
+
+// expand replaces the variables referenced in s. expanding holds the variables whose custom all
+// values are being expanded; references to them are kept, so cyclic all values terminate.
+func (ip *Interpolator) expand(s, datasourceType string, sc scope, expanding []string) string {
+	return variableRef.ReplaceAllStringFunc(s, func(match string) string {
+		m := variableRef.FindStringSubmatch(match)
+		name := m[1] + m[2] + m[4]
+		format := m[3] + m[6]
+		if m[5] != "" {
+			// Field paths such as ${__field.labels.job} are only known while rendering.
+			return match
+		}
+
+		val, ok := sc[name]
+		if !ok {
+			val, ok = ip.values[name]
+		}
+		if !ok {
+			b, ok := ip.builtins[name]
+			if !ok {
+				return match
+			}
+			val = value{name: name, values: []string{b}, texts: []string{b}}
+		}
+		if val.customAll && format != FormatText && format != FormatPercentEncode {
+			// A custom all value, e.g. .*, may reference other variables but is not formatted.
+			if slices.Contains(expanding, name) {
+				return match
+			}
+			return ip.expand(val.first(), datasourceType, sc, append(expanding[:len(expanding):len(expanding)], name))
+		}
+
+		if f, ok := formats[format]; ok {
+			return f(val)
+		}
+		if format == "" {
+			if f, ok := datasourceFormats[datasourceType]; ok {
+				return f(val)
+			}
+		}
+		return formats[FormatGlob](val)
+	})
+}
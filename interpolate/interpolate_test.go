@@ -0,0 +1,216 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interpolate
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+const testDashboard = `{
+  "title": "Pods",
+  "templating": {"list": [
+    {"name": "ds", "type": "datasource", "query": "prometheus", "current": {"text": "Prometheus", "value": "P1"}},
+    {"name": "ns", "type": "query", "multi": true, "includeAll": true,
+     "current": {"text": ["Alpha", "Beta"], "value": ["a", "b"]},
+     "options": [{"text": "All", "value": "$__all"}, {"text": "Alpha", "value": "a"}, {"text": "Beta", "value": "b"}, {"text": "Gamma", "value": "c"}]},
+    {"name": "single", "type": "custom", "query": "x'y", "current": {"text": "x'y", "value": "x'y"}},
+    {"name": "env", "type": "custom", "query": "dev,prod", "includeAll": true, "allValue": ".*", "current": {"text": "All", "value": "$__all"}},
+    {"name": "host", "type": "query", "multi": true, "includeAll": true, "current": {"text": "All", "value": ["$__all"]},
+     "options": [{"text": "All", "value": "$__all"}, {"text": "h1.example.com", "value": "h1.example.com"}, {"text": "h2", "value": "h2"}]},
+    {"name": "c", "type": "constant", "query": "42"}
+  ]},
+  "panels": [
+    {"id": 1, "type": "timeseries", "title": "Pods in $ns", "datasource": {"uid": "${ds}"}, "targets": [
+      {"refId": "A", "expr": "up{namespace=~\"$ns\", host=~\"$host\"}[$__rate_interval]", "legendFormat": "{{pod}}"},
+      {"refId": "B", "expr": "up", "hide": true}
+    ]},
+    {"id": 2, "type": "stat", "title": "Pods of ${ns:text}", "repeat": "ns", "datasource": {"type": "prometheus", "uid": "P1"}, "targets": [
+      {"refId": "A", "expr": "count(up{namespace=\"$ns\"})"}
+    ]},
+    {"id": 3, "type": "row", "title": "SQL", "collapsed": true, "panels": [
+      {"id": 4, "type": "table", "title": "Rows", "datasource": {"type": "grafana-postgresql-datasource", "uid": "PG"}, "targets": [
+        {"refId": "A", "rawSql": "SELECT * FROM pods WHERE ns IN ($ns) AND $__timeFilter(time) AND env ~ '$env'"}
+      ]}
+    ]}
+  ]
+}`
+
+func mustInterpolator(t *testing.T, opts Options) *Interpolator {
+	t.Helper()
+	d := &sdk.Dashboard{}
+	if err := json.Unmarshal([]byte(testDashboard), d); err != nil {
+		t.Fatalf("invalid dashboard: %v", err)
+	}
+	ip, err := New(d, opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return ip
+}
+
+func TestInterpolator_Replace(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ip := mustInterpolator(t, Options{From: from, To: from.Add(6 * time.Hour), Interval: 30 * time.Second})
+
+	tests := []struct {
+		s              string
+		datasourceType string
+		want           string
+	}{
+		{s: "$ns", want: "{a,b}"},
+		{s: "${ns}", want: "{a,b}"},
+		{s: "[[ns]]", want: "{a,b}"},
+		{s: "${ns:regex}", want: "(a|b)"},
+		{s: "${ns:pipe}", want: "a|b"},
+		{s: "${ns:csv}", want: "a,b"},
+		{s: "${ns:raw}", want: "a,b"},
+		{s: "[[ns:json]]", want: `["a","b"]`},
+		{s: "${ns:sqlstring}", want: "'a','b'"},
+		{s: "${ns:singlequote}", want: "'a','b'"},
+		{s: "${ns:doublequote}", want: `"a","b"`},
+		{s: "${ns:lucene}", want: `("a" OR "b")`},
+		{s: "${ns:percentencode}", want: "%7Ba%2Cb%7D"},
+		{s: "${ns:distributed}", want: "a,ns=b"},
+		{s: "${ns:queryparam}", want: "var-ns=a&var-ns=b"},
+		{s: "${ns:text}", want: "Alpha + Beta"},
+		{s: "${ns:unknown}", want: "{a,b}"},
+		{s: "$ns", datasourceType: "prometheus", want: "(a|b)"},
+		{s: "$ns", datasourceType: "loki", want: "a|b"},
+		{s: "$ns", datasourceType: "postgres", want: "'a','b'"},
+		{s: "$ns", datasourceType: "elasticsearch", want: `("a" OR "b")`},
+		{s: "${ns:csv}", datasourceType: "prometheus", want: "a,b"},
+		{s: "$single", want: "x'y"},
+		{s: "$single", datasourceType: "prometheus", want: `x\\'y`},
+		{s: "$single", datasourceType: "mysql", want: "x''y"},
+		{s: "${single:singlequote}", want: `'x\'y'`},
+		{s: "$host", datasourceType: "prometheus", want: `(h1\\.example\\.com|h2)`},
+		{s: "${host:regex}", want: `(h1\.example\.com|h2)`},
+		{s: "${host:text}", want: "All"},
+		{s: "$env", datasourceType: "prometheus", want: ".*"},
+		{s: "${env:regex}", want: ".*"},
+		{s: "${env:text}", want: "All"},
+		{s: "up{job=\"$c\"}", datasourceType: "prometheus", want: `up{job="42"}`},
+		{s: "${ds}", want: "P1"},
+		{s: "$unknown and ${__field.labels.job}", want: "$unknown and ${__field.labels.job}"},
+		{s: "$__interval $__interval_ms $__rate_interval", want: "30s 30000 1m"},
+		{s: "$__range $__range_s $__range_ms", want: "21600s 21600 21600000"},
+		{s: "$__from-$__to", want: "1704067200000-1704088800000"},
+		{s: "label_replace(up, \"x\", \"$1\", \"instance\", \"(.*)\")", want: "label_replace(up, \"x\", \"$1\", \"instance\", \"(.*)\")"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.datasourceType+" "+tt.s, func(t *testing.T) {
+			if got := ip.Replace(tt.s, tt.datasourceType); got != tt.want {
+				t.Errorf("Replace() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolator_Values(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		s    string
+		want string
+	}{
+		{
+			name: "Single selection of multi-value variable",
+			opts: Options{Values: map[string][]string{"ns": {"c"}}},
+			s:    "$ns ${ns:json} ${ns:text}",
+			want: `c ["c"] Gamma`,
+		},
+		{
+			name: "All",
+			opts: Options{Values: map[string][]string{"ns": {All}}},
+			s:    "$ns ${ns:text}",
+			want: "{a,b,c} All",
+		},
+		{
+			name: "All with choices",
+			opts: Options{Values: map[string][]string{"ns": {All}}, Choices: map[string][]string{"ns": {"x", "y"}}},
+			s:    "$ns",
+			want: "{x,y}",
+		},
+		{
+			name: "All of custom variable without options",
+			opts: Options{Values: map[string][]string{"single": {All}}},
+			s:    "${single:json}",
+			want: `["x'y"]`,
+		},
+		{
+			name: "Builtins without options are kept",
+			s:    "$__interval $__range",
+			want: "$__interval $__range",
+		},
+		{
+			name: "Small interval and scrape interval",
+			opts: Options{Interval: 500 * time.Millisecond, ScrapeInterval: 5 * time.Second},
+			s:    "$__interval $__rate_interval",
+			want: "500ms 20s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustInterpolator(t, tt.opts).Replace(tt.s, ""); got != tt.want {
+				t.Errorf("Replace() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_UnknownVariable(t *testing.T) {
+	d := &sdk.Dashboard{}
+	if err := json.Unmarshal([]byte(testDashboard), d); err != nil {
+		t.Fatalf("invalid dashboard: %v", err)
+	}
+	if _, err := New(d, Options{Values: map[string][]string{"namespace": {"a"}}}); err == nil {
+		t.Errorf("New() expected error for unknown variable")
+	}
+}
+
+func TestInterpolator_CyclicAllValues(t *testing.T) {
+	d := &sdk.Dashboard{}
+	data := `{"templating": {"list": [
+  {"name": "self", "type": "custom", "includeAll": true, "allValue": "a|$self", "current": {"text": "All", "value": "$__all"}},
+  {"name": "x", "type": "custom", "includeAll": true, "allValue": "x-$y", "current": {"text": "All", "value": "$__all"}},
+  {"name": "y", "type": "custom", "includeAll": true, "allValue": "y-$x", "current": {"text": "All", "value": "$__all"}}
+]}}`
+	if err := json.Unmarshal([]byte(data), d); err != nil {
+		t.Fatalf("invalid dashboard: %v", err)
+	}
+	ip, err := New(d, Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "$self", want: "a|$self"},
+		{s: "$x", want: "x-y-$x"},
+		{s: "${y}", want: "y-x-$y"},
+	}
+	for _, tt := range tests {
+		if got := ip.Replace(tt.s, "prometheus"); got != tt.want {
+			t.Errorf("Replace(%q) got = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
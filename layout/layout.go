@@ -0,0 +1,127 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package layout computes the gridPos of dashboard panels on the 24 column grid of Grafana.
+//
+// Panels are placed in order, left to right, starting a new line when a panel does not fit in the
+// remaining columns. Every panel then floats up until it touches a panel above it, the way Grafana
+// compacts a dashboard vertically, so panels never overlap and no space is wasted below short
+// panels. Rows always start below all panels placed before them and span the full width.
+package layout
+
+import (
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+const (
+	// Columns is the width of the grid.
+	Columns = 24
+	// RowHeight is the height of a row header.
+	RowHeight = 1
+	// DefaultWidth and DefaultHeight are the size of panels without size hint.
+	DefaultWidth  = 12
+	DefaultHeight = 8
+)
+
+// Grid places panels one after the other. The zero value is an empty grid.
+type Grid struct {
+	// heights holds, for every column, the y coordinate below the lowest panel in the column.
+	heights [Columns]int
+	// x is the column the next panel is placed at, if it fits in the remaining columns.
+	x int
+}
+
+// Place returns the position of the next panel of the given size. A width or height of 0 selects
+// the default size and widths above Columns are reduced to it.
+func (g *Grid) Place(w, h int) sdk.GridPos {
+	if w <= 0 {
+		w = DefaultWidth
+	}
+	if w > Columns {
+		w = Columns
+	}
+	if h <= 0 {
+		h = DefaultHeight
+	}
+	if g.x+w > Columns {
+		g.x = 0
+	}
+	pos := sdk.GridPos{X: g.x, Y: g.bottom(g.x, g.x+w), W: w, H: h}
+	for i := pos.X; i < pos.X+w; i++ {
+		g.heights[i] = pos.Y + h
+	}
+	g.x += w
+	return pos
+}
+
+// Row returns the position of a row header, below all panels placed so far. The panels placed
+// afterwards start below the header.
+func (g *Grid) Row() sdk.GridPos {
+	pos := sdk.GridPos{X: 0, Y: g.Height(), W: Columns, H: RowHeight}
+	for i := range g.heights {
+		g.heights[i] = pos.Y + RowHeight
+	}
+	g.x = 0
+	return pos
+}
+
+// Height returns the y coordinate below the lowest panel placed so far.
+func (g *Grid) Height() int {
+	return g.bottom(0, Columns)
+}
+
+func (g *Grid) bottom(from, to int) int {
+	y := 0
+	for _, h := range g.heights[from:to] {
+		y = max(y, h)
+	}
+	return y
+}
+
+// Panels lays out the panels in order, using their current width and height as size hints. The
+// panels of a collapsed row are laid out as if the row was expanded, but take no space until it is;
+// nested panels of other panels are not shown by Grafana and left untouched.
+func Panels(panels []sdk.Panel) {
+	var g Grid
+	for i := range panels {
+		p := &panels[i]
+		if !p.IsRow() {
+			p.GridPos = place(&g, p.GridPos)
+			continue
+		}
+		p.GridPos = keepStatic(g.Row(), p.GridPos)
+		if p.Collapsed {
+			nested := g
+			for j := range p.Panels {
+				p.Panels[j].GridPos = place(&nested, p.Panels[j].GridPos)
+			}
+		}
+	}
+}
+
+// Dashboard re-flows the panels of the dashboard, e.g. after panels were inserted or removed.
+func Dashboard(d *sdk.Dashboard) {
+	Panels(d.Panels)
+}
+
+func place(g *Grid, hint sdk.GridPos) sdk.GridPos {
+	return keepStatic(g.Place(hint.W, hint.H), hint)
+}
+
+func keepStatic(pos, old sdk.GridPos) sdk.GridPos {
+	pos.Static = old.Static
+	return pos
+}
@@ -0,0 +1,165 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package layout
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+func size(w, h int) sdk.GridPos {
+	return sdk.GridPos{W: w, H: h}
+}
+
+func pos(x, y, w, h int) sdk.GridPos {
+	return sdk.GridPos{X: x, Y: y, W: w, H: h}
+}
+
+func TestPanels(t *testing.T) {
+	tests := []struct {
+		name   string
+		panels []sdk.Panel
+		want   []sdk.Panel
+	}{
+		{
+			name: "Lines and default size",
+			panels: []sdk.Panel{
+				{ID: 1, GridPos: size(8, 4)},
+				{ID: 2, GridPos: size(8, 6)},
+				{ID: 3, GridPos: size(10, 4)},
+				{ID: 4},
+				{ID: 5, GridPos: size(30, 2)},
+			},
+			want: []sdk.Panel{
+				{ID: 1, GridPos: pos(0, 0, 8, 4)},
+				{ID: 2, GridPos: pos(8, 0, 8, 6)},
+				{ID: 3, GridPos: pos(0, 6, 10, 4)},
+				{ID: 4, GridPos: pos(10, 6, 12, 8)},
+				{ID: 5, GridPos: pos(0, 14, 24, 2)},
+			},
+		},
+		{
+			name: "Panels float up",
+			panels: []sdk.Panel{
+				{ID: 1, GridPos: size(12, 8)},
+				{ID: 2, GridPos: size(12, 4)},
+				{ID: 3, GridPos: size(12, 4)},
+				{ID: 4, GridPos: pos(0, 40, 12, 4)},
+			},
+			want: []sdk.Panel{
+				{ID: 1, GridPos: pos(0, 0, 12, 8)},
+				{ID: 2, GridPos: pos(12, 0, 12, 4)},
+				{ID: 3, GridPos: pos(0, 8, 12, 4)},
+				{ID: 4, GridPos: pos(12, 4, 12, 4)},
+			},
+		},
+		{
+			name: "Rows",
+			panels: []sdk.Panel{
+				{ID: 1, Type: "row", Title: "A"},
+				{ID: 2, GridPos: size(6, 4)},
+				{ID: 3, Type: "row", Title: "B", Collapsed: true, Panels: []sdk.Panel{
+					{ID: 4, GridPos: pos(0, 0, 24, 6)},
+					{ID: 5, GridPos: sdk.GridPos{W: 12, H: 3, Static: true}},
+				}},
+				{ID: 6, Type: "row", Title: "C"},
+				{ID: 7, GridPos: size(24, 8)},
+			},
+			want: []sdk.Panel{
+				{ID: 1, Type: "row", Title: "A", GridPos: pos(0, 0, 24, 1)},
+				{ID: 2, GridPos: pos(0, 1, 6, 4)},
+				{ID: 3, Type: "row", Title: "B", Collapsed: true, GridPos: pos(0, 5, 24, 1), Panels: []sdk.Panel{
+					{ID: 4, GridPos: pos(0, 6, 24, 6)},
+					{ID: 5, GridPos: sdk.GridPos{X: 0, Y: 12, W: 12, H: 3, Static: true}},
+				}},
+				{ID: 6, Type: "row", Title: "C", GridPos: pos(0, 6, 24, 1)},
+				{ID: 7, GridPos: pos(0, 7, 24, 8)},
+			},
+		},
+		{
+			name: "Nested panels of other panels are left untouched",
+			panels: []sdk.Panel{
+				{ID: 1, Type: "row", Title: "A", Panels: []sdk.Panel{{ID: 2, GridPos: pos(3, 3, 3, 3)}}},
+			},
+			want: []sdk.Panel{
+				{ID: 1, Type: "row", Title: "A", GridPos: pos(0, 0, 24, 1), Panels: []sdk.Panel{{ID: 2, GridPos: pos(3, 3, 3, 3)}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Panels(tt.panels)
+			if !reflect.DeepEqual(tt.panels, tt.want) {
+				t.Errorf("Panels() got = %+v, want %+v", tt.panels, tt.want)
+			}
+		})
+	}
+}
+
+func TestDashboard_Reflow(t *testing.T) {
+	data, err := os.ReadFile("../testdata/dashboard.yaml")
+	if err != nil {
+		t.Fatalf("failed to read json model, reason: %v", err)
+	}
+	d := &sdk.Dashboard{}
+	if err = json.Unmarshal(data, d); err != nil {
+		t.Fatalf("failed to parse json model, reason: %v", err)
+	}
+	Dashboard(d)
+	want, _ := json.Marshal(d)
+
+	// Re-flowing a laid out dashboard does not move any panel.
+	Dashboard(d)
+	if got, _ := json.Marshal(d); string(got) != string(want) {
+		t.Errorf("Dashboard() is not idempotent")
+	}
+
+	// Removing the first panel moves the others up, inserting it back restores the layout.
+	first := d.Panels[0]
+	d.Panels = d.Panels[1:]
+	Dashboard(d)
+	if len(d.Panels) > 0 && d.Panels[0].GridPos.Y != 0 {
+		t.Errorf("Dashboard() got first panel at %+v after removal", d.Panels[0].GridPos)
+	}
+	d.Panels = append([]sdk.Panel{first}, d.Panels...)
+	Dashboard(d)
+	if got, _ := json.Marshal(d); string(got) != string(want) {
+		t.Errorf("Dashboard() got = %s, want %s", got, want)
+	}
+}
+
+func TestGrid_Height(t *testing.T) {
+	var g Grid
+	if got := g.Height(); got != 0 {
+		t.Errorf("Height() got = %v, want 0", got)
+	}
+	g.Place(6, 4)
+	g.Place(6, 9)
+	if got := g.Height(); got != 9 {
+		t.Errorf("Height() got = %v, want 9", got)
+	}
+	if got := g.Row(); got != pos(0, 9, 24, 1) {
+		t.Errorf("Row() got = %+v", got)
+	}
+	if got := g.Place(0, 0); got != pos(0, 10, DefaultWidth, DefaultHeight) {
+		t.Errorf("Place() got = %+v", got)
+	}
+}
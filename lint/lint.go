@@ -0,0 +1,150 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lint checks dashboard models for mistakes before they are saved with SetDashboard.
+// Every check is a Rule; the built-in rules can be disabled individually and additional rules
+// can be passed in Options.
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	// SeverityError marks findings that break the dashboard or are rejected by Grafana.
+	SeverityError Severity = "error"
+	// SeverityWarning marks findings that make the dashboard harder to use or to share.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem found by a rule.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	// Path is the JSON path of the offending member, e.g. panels[2].panels[0].targets[1].expr.
+	Path    string
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Severity, f.Path, f.Message, f.Rule)
+}
+
+// Rule checks a dashboard.
+type Rule interface {
+	// Name identifies the rule in findings and in Options.Disabled.
+	Name() string
+	Check(d *sdk.Dashboard) []Finding
+}
+
+type ruleFunc struct {
+	name  string
+	check func(d *sdk.Dashboard) []Finding
+}
+
+func (r ruleFunc) Name() string {
+	return r.name
+}
+
+func (r ruleFunc) Check(d *sdk.Dashboard) []Finding {
+	return r.check(d)
+}
+
+// NewRule returns a rule with the given name that runs check.
+func NewRule(name string, check func(d *sdk.Dashboard) []Finding) Rule {
+	return ruleFunc{name: name, check: check}
+}
+
+// Options configures Dashboard.
+type Options struct {
+	// Disabled lists the names of the rules to skip, built-in or not.
+	Disabled []string
+	// Rules are run after the built-in rules.
+	Rules []Rule
+}
+
+// Dashboard runs the built-in rules and the rules of opts against the dashboard and returns the
+// findings in rule order. Findings without a rule name are attributed to the rule that returned them.
+func Dashboard(d *sdk.Dashboard, opts Options) []Finding {
+	disabled := map[string]bool{}
+	for _, name := range opts.Disabled {
+		disabled[name] = true
+	}
+
+	var findings []Finding
+	for _, r := range append(Rules(), opts.Rules...) {
+		if disabled[r.Name()] {
+			continue
+		}
+		for _, f := range r.Check(d) {
+			if f.Rule == "" {
+				f.Rule = r.Name()
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// HasErrors reports whether any of the findings has SeverityError.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// panelRef is a panel of the dashboard with its JSON path.
+type panelRef struct {
+	path  string
+	panel *sdk.Panel
+	// row is the row the panel is nested in, if any.
+	row *sdk.Panel
+}
+
+// panels returns the panels of the dashboard, including the ones nested in rows, in document order.
+func panels(d *sdk.Dashboard) []panelRef {
+	var out []panelRef
+	for i := range d.Panels {
+		p := &d.Panels[i]
+		path := fmt.Sprintf("panels[%d]", i)
+		out = append(out, panelRef{path: path, panel: p})
+		for j := range p.Panels {
+			out = append(out, panelRef{path: fmt.Sprintf("%s.panels[%d]", path, j), panel: &p.Panels[j], row: p})
+		}
+	}
+	return out
+}
+
+// describe names a panel in messages.
+func describe(p *sdk.Panel) string {
+	if p.Title == "" {
+		return fmt.Sprintf("panel %d", p.ID)
+	}
+	return fmt.Sprintf("panel %d (%q)", p.ID, p.Title)
+}
+
+func join(path ...string) string {
+	return strings.Join(path, ".")
+}
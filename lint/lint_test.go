@@ -0,0 +1,171 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/internal/testutil"
+)
+
+func TestDashboard(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		opts Options
+		want []Finding
+	}{
+		{
+			name: "Clean",
+			data: `{
+  "uid": "node-exporter_1", "title": "Nodes",
+  "annotations": {"list": [{"builtIn": 1, "datasource": {"type": "grafana", "uid": "-- Grafana --"}}]},
+  "templating": {"list": [
+    {"name": "ds", "type": "datasource", "query": "prometheus"},
+    {"name": "node", "type": "query", "datasource": {"uid": "${ds}"}, "query": "label_values(up{job=~\"$job|node\"}, instance)"},
+    {"name": "job", "type": "custom", "query": "node,node-exporter"}
+  ]},
+  "panels": [
+    {"id": 1, "type": "row", "title": "CPU", "gridPos": {"x": 0, "y": 0, "w": 24, "h": 1}},
+    {"id": 2, "type": "timeseries", "title": "CPU on $node", "datasource": {"uid": "$ds"}, "repeat": "node", "gridPos": {"x": 0, "y": 1, "w": 12, "h": 8},
+     "targets": [{"refId": "A", "datasource": {"uid": "$ds"}, "expr": "rate(node_cpu_seconds_total{instance=~\"${node:regex}\"}[$__rate_interval])", "legendFormat": "{{cpu}}"}]},
+    {"id": 3, "type": "stat", "title": "Uptime", "datasource": {"uid": "-- Mixed --"}, "gridPos": {"x": 12, "y": 1, "w": 12, "h": 8},
+     "targets": [{"refId": "A", "datasource": {"uid": "[[ds]]"}, "expr": "label_replace(up, \"x\", \"$1\", \"instance\", \"(.*)\")"}]},
+    {"id": 4, "type": "row", "title": "Memory", "collapsed": true, "gridPos": {"x": 0, "y": 9, "w": 24, "h": 1}, "panels": [
+      {"id": 5, "type": "timeseries", "title": "Memory", "gridPos": {"x": 0, "y": 10, "w": 24, "h": 8}}
+    ]}
+  ]
+}`,
+		},
+		{
+			name: "Legacy built-in variables",
+			data: `{
+  "title": "Requests",
+  "panels": [
+    {"id": 1, "type": "timeseries", "title": "Requests per $interval", "datasource": {"uid": "-- Mixed --"},
+     "targets": [{"refId": "A", "datasource": {"type": "grafana"}, "query": "SELECT count(\"value\") FROM \"requests\" WHERE $timeFilter AND time >= $timeFrom AND time <= $timeTo GROUP BY time($interval)"}]}
+  ]
+}`,
+		},
+		{
+			name: "Duplicate ids and overlapping panels",
+			data: `{
+  "title": "Nodes",
+  "panels": [
+    {"id": 1, "type": "stat", "title": "A", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+    {"id": 2, "type": "stat", "title": "B", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+    {"id": 1, "type": "stat", "title": "C", "gridPos": {"x": 6, "y": 4, "w": 6, "h": 8}},
+    {"id": 3, "type": "row", "title": "More", "collapsed": true, "gridPos": {"x": 0, "y": 12, "w": 24, "h": 1}, "panels": [
+      {"id": 2, "type": "stat", "title": "D", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+      {"id": 4, "type": "stat", "title": "E", "gridPos": {"x": 8, "y": 0, "w": 12, "h": 8}}
+    ]}
+  ]
+}`,
+			want: []Finding{
+				{Rule: RuleDuplicatePanelID, Severity: SeverityError, Path: "panels[2].id", Message: "panel id 1 is already used by panels[0]"},
+				{Rule: RuleDuplicatePanelID, Severity: SeverityError, Path: "panels[3].panels[0].id", Message: "panel id 2 is already used by panels[1]"},
+				{Rule: RuleOverlappingGridPos, Severity: SeverityError, Path: "panels[2].gridPos", Message: `panel 1 ("C") overlaps panel 1 ("A") at panels[0]`},
+				{Rule: RuleOverlappingGridPos, Severity: SeverityError, Path: "panels[3].panels[1].gridPos", Message: `panel 4 ("E") overlaps panel 2 ("D") at panels[3].panels[0]`},
+			},
+		},
+		{
+			name: "Undefined variables and hard-coded datasources",
+			data: `{
+  "title": "Nodes",
+  "annotations": {"list": [{"name": "Deploys", "datasource": "Loki"}]},
+  "templating": {"list": [{"name": "node", "type": "query", "datasource": {"type": "prometheus", "uid": "P1"}, "query": "label_values(up{cluster=\"$cluster\"}, instance)"}]},
+  "panels": [
+    {"id": 1, "type": "timeseries", "title": "CPU of [[node]] in ${region:text}", "repeat": "zone", "datasource": "Prometheus",
+     "targets": [{"refId": "A", "expr": "up{instance=\"$node\", job=\"$job\"}"}]}
+  ]
+}`,
+			want: []Finding{
+				{Rule: RuleUndefinedVariable, Severity: SeverityError, Path: "templating.list[0].query", Message: `variable "cluster" is not defined`},
+				{Rule: RuleUndefinedVariable, Severity: SeverityError, Path: "panels[0].title", Message: `variable "region" is not defined`},
+				{Rule: RuleUndefinedVariable, Severity: SeverityError, Path: "panels[0].repeat", Message: `variable "zone" is not defined`},
+				{Rule: RuleUndefinedVariable, Severity: SeverityError, Path: "panels[0].targets[0].expr", Message: `variable "job" is not defined`},
+				{Rule: RuleHardcodedDatasource, Severity: SeverityWarning, Path: "annotations.list[0].datasource", Message: `datasource "Loki" is hard-coded, use a datasource variable instead`},
+				{Rule: RuleHardcodedDatasource, Severity: SeverityWarning, Path: "templating.list[0].datasource", Message: `datasource "P1" is hard-coded, use a datasource variable instead`},
+				{Rule: RuleHardcodedDatasource, Severity: SeverityWarning, Path: "panels[0].datasource", Message: `datasource "Prometheus" is hard-coded, use a datasource variable instead`},
+			},
+		},
+		{
+			name: "Missing titles, orphaned panels and invalid uid",
+			data: `{
+  "uid": "this uid/is-far-too-long-for-grafana-to-accept",
+  "panels": [
+    {"id": 1, "type": "row", "gridPos": {"x": 0, "y": 0, "w": 24, "h": 1}, "panels": [{"id": 2, "type": "text", "title": "Notes"}]},
+    {"id": 3, "type": "timeseries", "gridPos": {"x": 0, "y": 1, "w": 24, "h": 8}, "panels": [{"id": 4, "type": "text", "title": "Notes"}]}
+  ]
+}`,
+			want: []Finding{
+				{Rule: RuleMissingTitle, Severity: SeverityError, Path: "title", Message: "dashboard has no title"},
+				{Rule: RuleMissingTitle, Severity: SeverityWarning, Path: "panels[1].title", Message: "panel 3 has no title"},
+				{Rule: RuleOrphanedRowPanel, Severity: SeverityError, Path: "panels[0].panels", Message: `1 nested panels are not shown, row "" is expanded`},
+				{Rule: RuleOrphanedRowPanel, Severity: SeverityError, Path: "panels[1].panels", Message: "1 nested panels are not shown, panel 3 is not a row"},
+				{Rule: RuleInvalidUID, Severity: SeverityError, Path: "uid", Message: "uid is 46 characters long, at most 40 are allowed"},
+				{Rule: RuleInvalidUID, Severity: SeverityError, Path: "uid", Message: `uid "this uid/is-far-too-long-for-grafana-to-accept" may only contain letters, digits, - and _`},
+			},
+		},
+		{
+			name: "Disabled and custom rules",
+			data: `{"uid": "a b", "tags": ["team-a"], "panels": [{"id": 1, "type": "stat", "title": "A"}]}`,
+			opts: Options{
+				Disabled: []string{RuleMissingTitle, RuleInvalidUID},
+				Rules: []Rule{
+					NewRule("owner-tag", func(d *sdk.Dashboard) []Finding {
+						for _, tag := range d.Tags {
+							if tag == "owner" {
+								return nil
+							}
+						}
+						return []Finding{{Severity: SeverityWarning, Path: "tags", Message: "dashboard has no owner tag"}}
+					}),
+				},
+			},
+			want: []Finding{
+				{Rule: "owner-tag", Severity: SeverityWarning, Path: "tags", Message: "dashboard has no owner tag"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Dashboard(testutil.MustUnmarshal[sdk.Dashboard](t, tt.data), tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dashboard() got = %+v, want %+v", got, tt.want)
+			}
+			if HasErrors(got) != HasErrors(tt.want) {
+				t.Errorf("HasErrors() got = %v", HasErrors(got))
+			}
+		})
+	}
+}
+
+func TestDashboard_Testdata(t *testing.T) {
+	data, err := os.ReadFile("../testdata/dashboard.yaml")
+	if err != nil {
+		t.Fatalf("failed to read json model, reason: %v", err)
+	}
+	for _, f := range Dashboard(testutil.MustUnmarshal[sdk.Dashboard](t, string(data)), Options{Disabled: []string{RuleHardcodedDatasource}}) {
+		if f.Severity == SeverityError {
+			t.Errorf("Dashboard() got unexpected finding %v", f)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+// Names of the built-in rules.
+const (
+	RuleDuplicatePanelID    = "duplicate-panel-id"
+	RuleOverlappingGridPos  = "overlapping-grid-pos"
+	RuleUndefinedVariable   = "undefined-variable"
+	RuleHardcodedDatasource = "hardcoded-datasource"
+	RuleMissingTitle        = "missing-title"
+	RuleOrphanedRowPanel    = "orphaned-row-panel"
+	RuleInvalidUID          = "invalid-uid"
+)
+
+const (
+	maxUIDLength          = 40
+	builtinVariablePrefix = "__"
+)
+
+// legacyBuiltinVariables are the built-in variables Grafana still supports without the __ prefix,
+// e.g. in InfluxQL and Graphite queries.
+var legacyBuiltinVariables = map[string]bool{
+	"timeFilter": true,
+	"interval":   true,
+	"timeFrom":   true,
+	"timeTo":     true,
+}
+
+// Rules returns the built-in rules.
+func Rules() []Rule {
+	return []Rule{
+		NewRule(RuleDuplicatePanelID, duplicatePanelIDs),
+		NewRule(RuleOverlappingGridPos, overlappingGridPos),
+		NewRule(RuleUndefinedVariable, undefinedVariables),
+		NewRule(RuleHardcodedDatasource, hardcodedDatasources),
+		NewRule(RuleMissingTitle, missingTitles),
+		NewRule(RuleOrphanedRowPanel, orphanedRowPanels),
+		NewRule(RuleInvalidUID, invalidUID),
+	}
+}
+
+// duplicatePanelIDs reports panels sharing the id of an earlier panel. Grafana uses panel ids
+// in links and for panel state, so the later panel is not addressable.
+func duplicatePanelIDs(d *sdk.Dashboard) []Finding {
+	var findings []Finding
+	seen := map[int]string{}
+	for _, ref := range panels(d) {
+		id := ref.panel.ID
+		if id == 0 {
+			continue
+		}
+		if first, ok := seen[id]; ok {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Path:     join(ref.path, "id"),
+				Message:  fmt.Sprintf("panel id %d is already used by %s", id, first),
+			})
+			continue
+		}
+		seen[id] = ref.path
+	}
+	return findings
+}
+
+// overlappingGridPos reports panels whose gridPos overlaps the one of an earlier panel. Panels of
+// collapsed rows are only compared with each other, as they are laid out when the row is expanded.
+func overlappingGridPos(d *sdk.Dashboard) []Finding {
+	var findings []Finding
+	check := func(refs []panelRef) {
+		for i, a := range refs {
+			for _, b := range refs[:i] {
+				if overlaps(a.panel.GridPos, b.panel.GridPos) {
+					findings = append(findings, Finding{
+						Severity: SeverityError,
+						Path:     join(a.path, "gridPos"),
+						Message:  fmt.Sprintf("%s overlaps %s at %s", describe(a.panel), describe(b.panel), b.path),
+					})
+				}
+			}
+		}
+	}
+
+	var top []panelRef
+	nested := map[*sdk.Panel][]panelRef{}
+	for _, ref := range panels(d) {
+		if ref.row == nil {
+			top = append(top, ref)
+		} else {
+			nested[ref.row] = append(nested[ref.row], ref)
+		}
+	}
+	check(top)
+	for i := range d.Panels {
+		check(nested[&d.Panels[i]])
+	}
+	return findings
+}
+
+func overlaps(a, b sdk.GridPos) bool {
+	if a.W == 0 || a.H == 0 || b.W == 0 || b.H == 0 {
+		return false
+	}
+	return a.X < b.X+b.W && b.X < a.X+a.W && a.Y < b.Y+b.H && b.Y < a.Y+a.H
+}
+
+// variableRef matches the $var, ${var}, ${var.field}, ${var:format} and [[var]] syntaxes.
+var variableRef = regexp.MustCompile(`\$(\w+)|\$\{(\w+)(?:\.[^:}]+)?(?::[^}]+)?\}|\[\[(\w+?)(?::\w+)?\]\]`)
+
+// referencedVariables returns the names of the variables referenced by s. Built-in variables such as
+// $__interval or $timeFilter and numbered capture group references such as $1 are ignored.
+func referencedVariables(s string) []string {
+	var names []string
+	for _, m := range variableRef.FindAllStringSubmatch(s, -1) {
+		name := m[1] + m[2] + m[3]
+		if strings.HasPrefix(name, builtinVariablePrefix) || legacyBuiltinVariables[name] || strings.Trim(name, "0123456789") == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// undefinedVariables reports references to template variables that the dashboard does not define.
+func undefinedVariables(d *sdk.Dashboard) []Finding {
+	defined := map[string]bool{}
+	if d.Templating != nil {
+		for _, v := range d.Templating.List {
+			defined[v.Name] = true
+		}
+	}
+
+	var findings []Finding
+	check := func(path, s string) {
+		for _, name := range referencedVariables(s) {
+			if !defined[name] {
+				findings = append(findings, Finding{
+					Severity: SeverityError,
+					Path:     path,
+					Message:  fmt.Sprintf("variable %q is not defined", name),
+				})
+			}
+		}
+	}
+	checkDatasource := func(path string, ref *sdk.DataSourceRef) {
+		if ref != nil {
+			check(join(path, "datasource"), ref.UID+ref.Name)
+		}
+	}
+
+	if d.Templating != nil {
+		for i, v := range d.Templating.List {
+			path := fmt.Sprintf("templating.list[%d]", i)
+			checkDatasource(path, v.Datasource)
+			if q, ok := v.Query.(string); ok && v.Type == "query" {
+				check(join(path, "query"), q)
+			}
+			check(join(path, "regex"), v.Regex)
+		}
+	}
+	for _, ref := range panels(d) {
+		p := ref.panel
+		check(join(ref.path, "title"), p.Title)
+		check(join(ref.path, "description"), p.Description)
+		check(join(ref.path, "interval"), p.Interval)
+		checkDatasource(ref.path, p.Datasource)
+		if p.Repeat != "" && !defined[p.Repeat] {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Path:     join(ref.path, "repeat"),
+				Message:  fmt.Sprintf("variable %q is not defined", p.Repeat),
+			})
+		}
+		for i, l := range p.Links {
+			check(fmt.Sprintf("%s.links[%d].url", ref.path, i), l.URL)
+		}
+		for i, t := range p.Targets {
+			path := fmt.Sprintf("%s.targets[%d]", ref.path, i)
+			checkDatasource(path, t.Datasource)
+			check(join(path, "expr"), t.Expr)
+			check(join(path, "query"), t.Query)
+			check(join(path, "rawSql"), t.RawSQL)
+			check(join(path, "legendFormat"), t.LegendFormat)
+			check(join(path, "interval"), t.Interval)
+		}
+	}
+	return findings
+}
+
+// hardcodedDatasources reports datasources referenced by uid or name instead of through a datasource
+// variable, which breaks the dashboard in every Grafana where the uid or name differs.
+func hardcodedDatasources(d *sdk.Dashboard) []Finding {
+	var findings []Finding
+	check := func(path string, ref *sdk.DataSourceRef) {
+		if ref == nil || ref.IsBuiltin() {
+			return
+		}
+		id := ref.UID
+		if id == "" {
+			id = ref.Name
+		}
+		if id == "" || variableRef.MatchString(id) {
+			return
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Path:     join(path, "datasource"),
+			Message:  fmt.Sprintf("datasource %q is hard-coded, use a datasource variable instead", id),
+		})
+	}
+
+	if d.Annotations != nil {
+		for i, a := range d.Annotations.List {
+			if a.BuiltIn == 0 {
+				check(fmt.Sprintf("annotations.list[%d]", i), a.Datasource)
+			}
+		}
+	}
+	if d.Templating != nil {
+		for i, v := range d.Templating.List {
+			check(fmt.Sprintf("templating.list[%d]", i), v.Datasource)
+		}
+	}
+	for _, ref := range panels(d) {
+		check(ref.path, ref.panel.Datasource)
+		for i, t := range ref.panel.Targets {
+			check(fmt.Sprintf("%s.targets[%d]", ref.path, i), t.Datasource)
+		}
+	}
+	return findings
+}
+
+// missingTitles reports a dashboard without title, which Grafana rejects, and panels without title.
+func missingTitles(d *sdk.Dashboard) []Finding {
+	var findings []Finding
+	if strings.TrimSpace(d.Title) == "" {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Path:     "title",
+			Message:  "dashboard has no title",
+		})
+	}
+	for _, ref := range panels(d) {
+		if !ref.panel.IsRow() && strings.TrimSpace(ref.panel.Title) == "" {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Path:     join(ref.path, "title"),
+				Message:  fmt.Sprintf("%s has no title", describe(ref.panel)),
+			})
+		}
+	}
+	return findings
+}
+
+// orphanedRowPanels reports nested panels that Grafana does not show: the panels of an expanded row
+// belong after it in the top level list, and only rows can hold panels.
+func orphanedRowPanels(d *sdk.Dashboard) []Finding {
+	var findings []Finding
+	for i := range d.Panels {
+		p := &d.Panels[i]
+		if len(p.Panels) == 0 {
+			continue
+		}
+		var reason string
+		switch {
+		case !p.IsRow():
+			reason = fmt.Sprintf("%s is not a row", describe(p))
+		case !p.Collapsed:
+			reason = fmt.Sprintf("row %q is expanded", p.Title)
+		default:
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Path:     fmt.Sprintf("panels[%d].panels", i),
+			Message:  fmt.Sprintf("%d nested panels are not shown, %s", len(p.Panels), reason),
+		})
+	}
+	return findings
+}
+
+var uidChars = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// invalidUID reports uids that Grafana rejects. An empty uid is valid, Grafana generates one.
+func invalidUID(d *sdk.Dashboard) []Finding {
+	var findings []Finding
+	if len(d.UID) > maxUIDLength {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Path:     "uid",
+			Message:  fmt.Sprintf("uid is %d characters long, at most %d are allowed", len(d.UID), maxUIDLength),
+		})
+	}
+	if d.UID != "" && !uidChars.MatchString(d.UID) {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Path:     "uid",
+			Message:  fmt.Sprintf("uid %q may only contain letters, digits, - and _", d.UID),
+		})
+	}
+	return findings
+}
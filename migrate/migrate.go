@@ -0,0 +1,237 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migrate upgrades dashboard models to the current schemaVersion without a browser, applying
+// the migrations the Grafana frontend runs when a dashboard is opened. It also converts the Angular
+// graph, singlestat and table-old panels to their React replacements.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+// LatestSchemaVersion is the schemaVersion dashboards are migrated to.
+const LatestSchemaVersion = 39
+
+// Datasource describes a datasource of the Grafana the dashboard is migrated for. Migrations to
+// schemaVersion 33 and 36 use them to replace datasource names by references.
+type Datasource struct {
+	UID       string
+	Name      string
+	Type      string
+	IsDefault bool
+}
+
+// Options configures Dashboard.
+type Options struct {
+	// Datasources are used to resolve datasource names. Names that are not found are kept as the uid of the
+	// reference, and panels using the default datasource keep a null reference if none is marked as default.
+	Datasources []Datasource
+	// KeepAngularPanels disables the conversion of graph and table-old panels. Singlestat panels are
+	// always converted, as part of the migration to schemaVersion 28.
+	KeepAngularPanels bool
+}
+
+// PanelConversion records a panel converted to another panel plugin.
+type PanelConversion struct {
+	ID    int
+	Title string
+	From  string
+	To    string
+}
+
+// Result is the outcome of Dashboard.
+type Result struct {
+	Dashboard   *sdk.Dashboard
+	FromVersion int
+	ToVersion   int
+	Conversions []PanelConversion
+}
+
+type object = map[string]any
+
+type step struct {
+	version   int
+	dashboard func(m *migrator, d object)
+	panel     func(m *migrator, p object)
+}
+
+// steps are the schema migrations, applied in order to dashboards with a lower schemaVersion.
+// Versions that only touch plugins or features this package does not model are not listed.
+var steps = []step{
+	{version: 3, dashboard: (*migrator).assignPanelIDs},
+	{version: 13, panel: (*migrator).gridThresholds},
+	{version: 14, dashboard: (*migrator).sharedCrosshair},
+	{version: 16, dashboard: (*migrator).rowsToGrid},
+	{version: 17, panel: (*migrator).minSpan},
+	{version: 19, panel: (*migrator).panelLinks},
+	{version: 20, panel: (*migrator).dataLinkVariables},
+	{version: 21, panel: (*migrator).dataLinkLabels},
+	{version: 24, panel: (*migrator).angularTable},
+	{version: 26, panel: (*migrator).textPanel},
+	{version: 27, dashboard: (*migrator).constantVariables},
+	{version: 28, dashboard: (*migrator).variableTags, panel: (*migrator).singlestat},
+	{version: 29, dashboard: (*migrator).queryVariableRefresh},
+	{version: 30, panel: (*migrator).valueMappingsAndTooltip},
+	{version: 31, panel: (*migrator).labelsToFields},
+	{version: 33, dashboard: (*migrator).datasourceRefs, panel: (*migrator).panelDatasourceRefs},
+	{version: 35, panel: (*migrator).xAxisVisibility},
+	{version: 36, dashboard: (*migrator).defaultDatasourceRefs, panel: (*migrator).panelDefaultDatasourceRefs},
+	{version: 37, panel: (*migrator).hiddenLegend},
+	{version: 38, panel: (*migrator).tableCellOptions},
+}
+
+type migrator struct {
+	opts   Options
+	result *Result
+}
+
+// Dashboard migrates a copy of the dashboard to LatestSchemaVersion. Dashboards at or above it are only
+// checked for Angular panels.
+func Dashboard(d *sdk.Dashboard, opts Options) (*Result, error) {
+	if d == nil {
+		return nil, errors.New("missing dashboard model")
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj object
+	if err = dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	from, _ := number(obj["schemaVersion"])
+	m := &migrator{opts: opts, result: &Result{FromVersion: int(from), ToVersion: int(from)}}
+	for _, s := range steps {
+		if int(from) >= s.version {
+			continue
+		}
+		if s.dashboard != nil {
+			s.dashboard(m, obj)
+		}
+		if s.panel != nil {
+			for _, p := range allPanels(obj) {
+				s.panel(m, p)
+			}
+		}
+	}
+	if int(from) < LatestSchemaVersion {
+		obj["schemaVersion"] = LatestSchemaVersion
+		m.result.ToVersion = LatestSchemaVersion
+	}
+	if !opts.KeepAngularPanels {
+		for _, p := range allPanels(obj) {
+			m.convertAngularPanel(p)
+		}
+	}
+
+	if data, err = json.Marshal(obj); err != nil {
+		return nil, err
+	}
+	m.result.Dashboard = &sdk.Dashboard{}
+	if err = json.Unmarshal(data, m.result.Dashboard); err != nil {
+		return nil, fmt.Errorf("failed to decode migrated dashboard, reason: %w", err)
+	}
+	return m.result, nil
+}
+
+func (m *migrator) converted(p object, from, to string) {
+	id, _ := number(p["id"])
+	m.result.Conversions = append(m.result.Conversions, PanelConversion{
+		ID:    int(id),
+		Title: str(p["title"]),
+		From:  from,
+		To:    to,
+	})
+	p["type"] = to
+}
+
+// allPanels returns the panels of the dashboard, including the ones nested in collapsed rows and,
+// for dashboards below schemaVersion 16, the ones in legacy rows.
+func allPanels(d object) []object {
+	var out []object
+	for _, row := range objects(d["rows"]) {
+		out = append(out, objects(row["panels"])...)
+	}
+	for _, p := range objects(d["panels"]) {
+		out = append(out, p)
+		out = append(out, objects(p["panels"])...)
+	}
+	return out
+}
+
+// objects returns the JSON objects held by the array v.
+func objects(v any) []object {
+	list, _ := v.([]any)
+	out := make([]object, 0, len(list))
+	for _, item := range list {
+		if obj, ok := item.(object); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// child returns the object held by parent[key], creating it if missing.
+func child(parent object, key string) object {
+	if obj, ok := parent[key].(object); ok {
+		return obj
+	}
+	obj := object{}
+	parent[key] = obj
+	return obj
+}
+
+func str(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+func boolean(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// number returns the value of a JSON number, or of a string holding one.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
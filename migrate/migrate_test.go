@@ -0,0 +1,293 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/internal/testutil"
+)
+
+// get returns the member of the encoded dashboard at the dot separated path, e.g. panels.0.type.
+func get(t *testing.T, d *sdk.Dashboard, path string) any {
+	t.Helper()
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var v any
+	if err = json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+func TestDashboard_LegacyRows(t *testing.T) {
+	d := testutil.MustUnmarshal[sdk.Dashboard](t, `{
+  "schemaVersion": 12, "title": "Legacy", "sharedCrosshair": true,
+  "rows": [
+    {"title": "Overview", "showTitle": true, "height": "250px", "panels": [
+      {"id": 1, "type": "singlestat", "title": "Up", "span": 4, "format": "none", "valueName": "current", "thresholds": "1,2", "colorBackground": true,
+       "valueMaps": [{"value": "1", "op": "=", "text": "UP"}], "mappingType": 1, "sparkline": {"show": true}},
+      {"id": 2, "type": "graph", "title": "CPU", "span": 8, "lines": true, "fill": 1, "linewidth": 2, "stack": true,
+       "grid": {"threshold1": 80, "threshold1Color": "rgba(216, 200, 27, 0.27)", "threshold2": null},
+       "yaxes": [{"format": "percent", "min": 0, "logBase": 1, "show": true}, {"format": "short", "show": true}],
+       "seriesOverrides": [{"alias": "/idle/", "yaxis": 2}],
+       "legend": {"show": true, "alignAsTable": true, "avg": true, "current": true},
+       "links": [{"type": "dashboard", "dashboard": "Node Details", "title": "Details", "keepTime": true}]}
+    ]},
+    {"title": "Details", "collapse": true, "panels": [
+      {"id": 3, "type": "text", "title": "Notes", "span": 12, "height": 100, "content": "# Notes", "mode": "markdown"}
+    ]}
+  ]
+}`)
+	res, err := Dashboard(d, Options{})
+	if err != nil {
+		t.Fatalf("Dashboard() error = %v", err)
+	}
+	if res.FromVersion != 12 || res.ToVersion != LatestSchemaVersion || res.Dashboard.SchemaVersion != LatestSchemaVersion {
+		t.Errorf("Dashboard() versions got = %v -> %v, schemaVersion %v", res.FromVersion, res.ToVersion, res.Dashboard.SchemaVersion)
+	}
+	wantConversions := []PanelConversion{
+		{ID: 1, Title: "Up", From: "singlestat", To: "stat"},
+		{ID: 2, Title: "CPU", From: "graph", To: "timeseries"},
+	}
+	if !reflect.DeepEqual(res.Conversions, wantConversions) {
+		t.Errorf("Dashboard() conversions got = %+v, want %+v", res.Conversions, wantConversions)
+	}
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"graphTooltip", 1.0},
+		{"rows", nil},
+		{"panels.0.type", "row"},
+		{"panels.0.id", 4.0},
+		{"panels.0.gridPos", map[string]any{"x": 0.0, "y": 0.0, "w": 24.0, "h": 7.0}},
+		{"panels.1.gridPos", map[string]any{"x": 0.0, "y": 1.0, "w": 8.0, "h": 7.0}},
+		{"panels.1.options.colorMode", "background"},
+		{"panels.1.options.graphMode", "area"},
+		{"panels.1.options.reduceOptions.calcs", []any{"lastNotNull"}},
+		{"panels.1.fieldConfig.defaults.thresholds.steps.2", map[string]any{"color": "#d44a3a", "value": 2.0}},
+		{"panels.1.fieldConfig.defaults.mappings.0.options.1.text", "UP"},
+		{"panels.2.gridPos", map[string]any{"x": 8.0, "y": 1.0, "w": 16.0, "h": 7.0}},
+		{"panels.2.fieldConfig.defaults.unit", "percent"},
+		{"panels.2.fieldConfig.defaults.custom.fillOpacity", 10.0},
+		{"panels.2.fieldConfig.defaults.custom.lineWidth", 2.0},
+		{"panels.2.fieldConfig.defaults.custom.stacking.mode", "normal"},
+		{"panels.2.fieldConfig.defaults.custom.thresholdsStyle.mode", "line+area"},
+		{"panels.2.fieldConfig.defaults.thresholds.steps.1.value", 80.0},
+		{"panels.2.fieldConfig.overrides.0.matcher", map[string]any{"id": "byRegexp", "options": "idle"}},
+		{"panels.2.options.legend", map[string]any{"showLegend": true, "displayMode": "table", "placement": "bottom", "calcs": []any{"mean", "lastNotNull"}}},
+		{"panels.2.links.0", map[string]any{"title": "Details", "url": "dashboard/db/node-details?$__url_time_range"}},
+		{"panels.2.yaxes", nil},
+		{"panels.3.type", "row"},
+		{"panels.3.collapsed", true},
+		{"panels.3.gridPos.y", 8.0},
+		{"panels.3.panels.0.id", 3.0},
+		{"panels.3.panels.0.gridPos", map[string]any{"x": 0.0, "y": 9.0, "w": 24.0, "h": 3.0}},
+		{"panels.3.panels.0.options", map[string]any{"content": "# Notes", "mode": "markdown"}},
+	}
+	for _, tt := range tests {
+		if got := get(t, res.Dashboard, tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s got = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDashboard_TableOld(t *testing.T) {
+	d := testutil.MustUnmarshal[sdk.Dashboard](t, `{
+  "schemaVersion": 22,
+  "panels": [
+    {"id": 1, "type": "table", "title": "Databases", "transform": "timeseries_aggregations", "columns": [{"text": "Current", "value": "current"}],
+     "styles": [
+       {"pattern": "/.*/", "type": "number", "unit": "bytes", "decimals": 1, "align": "right"},
+       {"pattern": "Time", "type": "hidden"},
+       {"pattern": "Status", "alias": "State", "type": "string", "colorMode": "cell", "thresholds": ["1"], "colors": ["red", "green"],
+        "link": true, "linkUrl": "/d/db?var-db=$__cell", "linkTooltip": "Open"}
+     ]},
+    {"id": 2, "type": "graph", "title": "Series", "xaxis": {"mode": "series"}}
+  ]
+}`)
+	tests := []struct {
+		name string
+		opts Options
+		want map[string]any
+	}{
+		{
+			name: "Convert",
+			want: map[string]any{
+				"panels.0.type":                                             "table",
+				"panels.0.styles":                                           nil,
+				"panels.0.options.showHeader":                               true,
+				"panels.0.transformations.0":                                map[string]any{"id": "reduce", "options": map[string]any{"reducers": []any{"lastNotNull"}}},
+				"panels.0.fieldConfig.defaults.unit":                        "bytes",
+				"panels.0.fieldConfig.defaults.custom.align":                "right",
+				"panels.0.fieldConfig.overrides.0.properties.0.id":          "custom.hidden",
+				"panels.0.fieldConfig.overrides.1.matcher":                  map[string]any{"id": "byName", "options": "Status"},
+				"panels.0.fieldConfig.overrides.1.properties.0":             map[string]any{"id": "displayName", "value": "State"},
+				"panels.0.fieldConfig.overrides.1.properties.3.value.0.url": "/d/db?var-db=${__value.text}",
+				"panels.1.type":                                             "graph",
+			},
+		},
+		{
+			name: "Keep Angular panels",
+			opts: Options{KeepAngularPanels: true},
+			want: map[string]any{
+				"panels.0.type":          "table-old",
+				"panels.0.styles.0.unit": "bytes",
+				"panels.1.type":          "graph",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Dashboard(d, tt.opts)
+			if err != nil {
+				t.Fatalf("Dashboard() error = %v", err)
+			}
+			for path, want := range tt.want {
+				if got := get(t, res.Dashboard, path); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s got = %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDashboard_DatasourceRefs(t *testing.T) {
+	d := testutil.MustUnmarshal[sdk.Dashboard](t, `{
+  "schemaVersion": 28,
+  "annotations": {"list": [{"builtIn": 1, "datasource": "-- Grafana --", "name": "Annotations & Alerts"}]},
+  "templating": {"list": [{"name": "ns", "type": "query", "datasource": "Prometheus", "options": [{"text": "a", "value": "a"}], "refresh": 0}]},
+  "panels": [
+    {"id": 1, "type": "timeseries", "datasource": "Prometheus", "targets": [{"refId": "A", "expr": "up"}]},
+    {"id": 2, "type": "timeseries", "targets": [{"refId": "A", "expr": "up"}]},
+    {"id": 3, "type": "stat", "datasource": "${ds}", "targets": [{"refId": "A", "datasource": "Unknown"}]},
+    {"id": 4, "type": "table", "fieldConfig": {"defaults": {"custom": {"displayMode": "color-background"}}, "overrides": []}, "options": {"legend": {"displayMode": "hidden"}}}
+  ]
+}`)
+	res, err := Dashboard(d, Options{Datasources: []Datasource{
+		{UID: "P1", Name: "Prometheus", Type: "prometheus", IsDefault: true},
+	}})
+	if err != nil {
+		t.Fatalf("Dashboard() error = %v", err)
+	}
+	prom := map[string]any{"type": "prometheus", "uid": "P1"}
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"annotations.list.0.datasource", map[string]any{"type": "grafana", "uid": "-- Grafana --"}},
+		{"templating.list.0.datasource", prom},
+		{"templating.list.0.refresh", 1.0},
+		{"templating.list.0.options", []any{}},
+		{"panels.0.datasource", prom},
+		{"panels.0.targets.0.datasource", prom},
+		{"panels.1.datasource", prom},
+		{"panels.1.targets.0.datasource", prom},
+		{"panels.2.datasource", map[string]any{"uid": "${ds}"}},
+		{"panels.2.targets.0.datasource", map[string]any{"uid": "Unknown"}},
+		{"panels.3.fieldConfig.defaults.custom", map[string]any{"cellOptions": map[string]any{"type": "color-background", "mode": "gradient"}}},
+		{"panels.3.options.legend", map[string]any{"displayMode": "list", "showLegend": false}},
+	}
+	for _, tt := range tests {
+		if got := get(t, res.Dashboard, tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s got = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestUpgradeValueMappings(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		want string
+	}{
+		{
+			name: "Values, ranges and null",
+			old:  `[{"id":0,"type":1,"value":"1","text":"On"},{"id":1,"type":1,"value":"null","text":"N/A"},{"id":2,"type":2,"from":"10","to":"20","text":"Mid"},{"id":3,"type":1,"value":"0","text":"Off"}]`,
+			want: `[{"type":"value","options":{"0":{"text":"Off","index":3},"1":{"text":"On","index":0}}},{"type":"special","options":{"match":"null","result":{"text":"N/A","index":1}}},{"type":"range","options":{"from":10,"to":20,"result":{"text":"Mid","index":2}}}]`,
+		},
+		{
+			name: "Already upgraded",
+			old:  `[{"type":"value","options":{"1":{"text":"On"}}}]`,
+			want: `[{"type":"value","options":{"1":{"text":"On"}}}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var old, want any
+			_ = json.Unmarshal([]byte(tt.old), &old)
+			_ = json.Unmarshal([]byte(tt.want), &want)
+			data, _ := json.Marshal(upgradeValueMappings(old))
+			var got any
+			_ = json.Unmarshal(data, &got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("upgradeValueMappings() got = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestDashboard_Testdata(t *testing.T) {
+	data, err := os.ReadFile("../testdata/dashboard.yaml")
+	if err != nil {
+		t.Fatalf("failed to read json model, reason: %v", err)
+	}
+	d := testutil.MustUnmarshal[sdk.Dashboard](t, string(data))
+	res, err := Dashboard(d, Options{})
+	if err != nil {
+		t.Fatalf("Dashboard() error = %v", err)
+	}
+	if res.FromVersion != 27 || res.Dashboard.SchemaVersion != LatestSchemaVersion || len(res.Conversions) != 0 {
+		t.Errorf("Dashboard() got from = %v, schemaVersion = %v, conversions = %+v", res.FromVersion, res.Dashboard.SchemaVersion, res.Conversions)
+	}
+	if len(res.Dashboard.AllPanels()) != len(d.AllPanels()) {
+		t.Errorf("Dashboard() panels got = %v, want %v", len(res.Dashboard.AllPanels()), len(d.AllPanels()))
+	}
+	again, err := Dashboard(res.Dashboard, Options{})
+	if err != nil {
+		t.Fatalf("Dashboard() error = %v", err)
+	}
+	a, _ := json.Marshal(res.Dashboard)
+	b, _ := json.Marshal(again.Dashboard)
+	if string(a) != string(b) {
+		t.Errorf("Dashboard() is not idempotent")
+	}
+}
@@ -0,0 +1,560 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultThresholdColors are the threshold colors of Angular singlestat and table panels.
+var defaultThresholdColors = []any{"#299c46", "rgba(237, 129, 40, 0.89)", "#d44a3a"}
+
+// property is a field config property, e.g. unit or custom.align.
+type property struct {
+	id    string
+	value any
+}
+
+// fieldConfig returns the field config of the panel, creating the missing parts.
+func fieldConfig(p object) (defaults object, overrides []any) {
+	fc := child(p, "fieldConfig")
+	defaults = child(fc, "defaults")
+	overrides, _ = fc["overrides"].([]any)
+	if overrides == nil {
+		overrides = []any{}
+	}
+	return defaults, overrides
+}
+
+func setOverrides(p object, overrides []any) {
+	child(p, "fieldConfig")["overrides"] = overrides
+}
+
+// setProperty sets the property with the dot separated id in the field config defaults.
+func setProperty(defaults object, id string, value any) {
+	keys := strings.Split(id, ".")
+	obj := defaults
+	for _, key := range keys[:len(keys)-1] {
+		obj = child(obj, key)
+	}
+	obj[keys[len(keys)-1]] = value
+}
+
+// fieldMatcher matches fields by name, or by regular expression if pattern is enclosed in slashes.
+func fieldMatcher(pattern string) object {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return object{"id": "byRegexp", "options": pattern[1 : len(pattern)-1]}
+	}
+	return object{"id": "byName", "options": pattern}
+}
+
+func override(pattern string, props []property) object {
+	list := make([]any, 0, len(props))
+	for _, prop := range props {
+		list = append(list, object{"id": prop.id, "value": prop.value})
+	}
+	return object{"matcher": fieldMatcher(pattern), "properties": list}
+}
+
+// thresholdSteps converts the thresholds and colors of Angular panels into threshold steps.
+func thresholdSteps(values []string, colors []any) object {
+	if len(colors) == 0 {
+		colors = defaultThresholdColors
+	}
+	steps := []any{object{"color": colors[0], "value": nil}}
+	for i, v := range values {
+		value, ok := number(strings.TrimSpace(v))
+		if !ok || i+1 >= len(colors) {
+			continue
+		}
+		steps = append(steps, object{"color": colors[i+1], "value": value})
+	}
+	return object{"mode": "absolute", "steps": steps}
+}
+
+// splitThresholds returns the threshold values of Angular panels, given either as "50,80" or as a list.
+func splitThresholds(v any) []string {
+	if s, ok := v.(string); ok {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		return strings.Split(s, ",")
+	}
+	var out []string
+	list, _ := v.([]any)
+	for _, item := range list {
+		out = append(out, itoa(item))
+	}
+	return out
+}
+
+// legacyMappings converts the value and range maps of Angular panels into value mappings.
+func legacyMappings(p object) []any {
+	var mappings []any
+	mappingType, _ := number(p["mappingType"])
+	if mappingType != 2 {
+		for _, vm := range objects(p["valueMaps"]) {
+			mappings = append(mappings, object{"type": 1, "value": str(vm["value"]), "text": str(vm["text"])})
+		}
+	}
+	if mappingType != 1 {
+		for _, rm := range objects(p["rangeMaps"]) {
+			mappings = append(mappings, object{"type": 2, "from": rm["from"], "to": rm["to"], "text": str(rm["text"])})
+		}
+	}
+	return upgradeValueMappings(mappings)
+}
+
+func deleteKeys(p object, keys ...string) {
+	for _, key := range keys {
+		delete(p, key)
+	}
+}
+
+// singlestat converts singlestat panels into stat panels, or into gauge panels if they show a gauge.
+func (m *migrator) singlestat(p object) {
+	from := str(p["type"])
+	if from != "singlestat" && from != "grafana-singlestat-panel" {
+		return
+	}
+	gauge, _ := p["gauge"].(object)
+	to := "stat"
+	if boolean(gauge["show"]) {
+		to = "gauge"
+	}
+
+	defaults, overrides := fieldConfig(p)
+	if unit := str(p["format"]); unit != "" {
+		defaults["unit"] = unit
+	}
+	if decimals, ok := number(p["decimals"]); ok {
+		defaults["decimals"] = decimals
+	}
+	if noValue := str(p["nullText"]); noValue != "" {
+		defaults["noValue"] = noValue
+	}
+	if to == "gauge" {
+		if v, ok := number(gauge["minValue"]); ok {
+			defaults["min"] = v
+		}
+		if v, ok := number(gauge["maxValue"]); ok {
+			defaults["max"] = v
+		}
+	}
+	colors, _ := p["colors"].([]any)
+	defaults["thresholds"] = thresholdSteps(splitThresholds(p["thresholds"]), colors)
+	if mappings := legacyMappings(p); len(mappings) > 0 {
+		defaults["mappings"] = mappings
+	}
+	setOverrides(p, overrides)
+
+	reduceOptions := object{"calcs": []any{reducer(str(p["valueName"]))}, "fields": "", "values": false}
+	if column := str(p["tableColumn"]); column != "" {
+		reduceOptions["fields"] = "/^" + regexp.QuoteMeta(column) + "$/"
+	}
+	options := object{"reduceOptions": reduceOptions, "orientation": "auto"}
+	if to == "gauge" {
+		options["showThresholdLabels"] = boolean(gauge["thresholdLabels"])
+		options["showThresholdMarkers"] = boolean(gauge["thresholdMarkers"])
+	} else {
+		colorMode := "none"
+		switch {
+		case boolean(p["colorBackground"]):
+			colorMode = "background"
+		case boolean(p["colorValue"]):
+			colorMode = "value"
+		}
+		graphMode := "none"
+		if sparkline, _ := p["sparkline"].(object); boolean(sparkline["show"]) {
+			graphMode = "area"
+		}
+		options["colorMode"] = colorMode
+		options["graphMode"] = graphMode
+		options["justifyMode"] = "auto"
+		options["textMode"] = "auto"
+		options["orientation"] = "horizontal"
+	}
+	p["options"] = options
+
+	deleteKeys(p, "colorBackground", "colorPostfix", "colorPrefix", "colorValue", "colors", "decimals",
+		"format", "gauge", "mappingType", "mappingTypes", "nullPointMode", "nullText", "postfix",
+		"postfixFontSize", "prefix", "prefixFontSize", "rangeMaps", "sparkline", "tableColumn",
+		"thresholds", "valueFontSize", "valueMaps", "valueName")
+	m.converted(p, from, to)
+}
+
+// convertAngularPanel converts graph and table-old panels into their React replacements.
+func (m *migrator) convertAngularPanel(p object) {
+	switch p["type"] {
+	case "graph":
+		m.graph(p)
+	case "table-old":
+		m.tableOld(p)
+	}
+}
+
+// graph converts graph panels with a time x axis into time series panels. Graphs showing series or
+// histograms on the x axis are left unchanged.
+func (m *migrator) graph(p object) {
+	xaxis, _ := p["xaxis"].(object)
+	if mode := str(xaxis["mode"]); mode != "" && mode != "time" {
+		return
+	}
+	defaults, overrides := fieldConfig(p)
+	custom := child(defaults, "custom")
+
+	lines := p["lines"] == nil || boolean(p["lines"])
+	switch {
+	case boolean(p["bars"]) && !lines:
+		custom["drawStyle"] = "bars"
+	case boolean(p["points"]) && !lines:
+		custom["drawStyle"] = "points"
+	default:
+		custom["drawStyle"] = "line"
+	}
+	custom["lineWidth"] = 1
+	if v, ok := number(p["linewidth"]); ok {
+		custom["lineWidth"] = v
+	}
+	custom["fillOpacity"] = 0
+	if v, ok := number(p["fill"]); ok {
+		custom["fillOpacity"] = v * 10
+	}
+	custom["gradientMode"] = "none"
+	if v, _ := number(p["fillGradient"]); v > 0 {
+		custom["gradientMode"] = "opacity"
+	}
+	custom["showPoints"] = "never"
+	if boolean(p["points"]) {
+		custom["showPoints"] = "always"
+	}
+	if v, ok := number(p["pointradius"]); ok {
+		custom["pointSize"] = 2 + v*2
+	}
+	custom["lineInterpolation"] = "linear"
+	if boolean(p["steppedLine"]) {
+		custom["lineInterpolation"] = "stepAfter"
+	}
+	custom["spanNulls"] = p["nullPointMode"] == "connected"
+	stacking := object{"mode": "none", "group": "A"}
+	if boolean(p["stack"]) {
+		stacking["mode"] = "normal"
+		if boolean(p["percentage"]) {
+			stacking["mode"] = "percent"
+		}
+	}
+	custom["stacking"] = stacking
+	if boolean(p["dashes"]) {
+		custom["lineStyle"] = dashStyle(p)
+	}
+	custom["axisPlacement"] = "auto"
+
+	yaxes := objects(p["yaxes"])
+	if len(yaxes) > 0 {
+		applyAxis(defaults, custom, yaxes[0])
+	}
+	if thresholds := objects(p["thresholds"]); len(thresholds) > 0 {
+		defaults["thresholds"], custom["thresholdsStyle"] = graphThresholds(thresholds)
+	}
+
+	options, _ := p["options"].(object)
+	if links, ok := options["dataLinks"].([]any); ok && len(links) > 0 {
+		defaults["links"] = links
+	}
+	legend, _ := p["legend"].(object)
+	legendOptions := object{
+		"showLegend":  legend == nil || legend["show"] == nil || boolean(legend["show"]),
+		"displayMode": "list",
+		"placement":   "bottom",
+		"calcs":       []any{},
+	}
+	if boolean(legend["alignAsTable"]) {
+		legendOptions["displayMode"] = "table"
+	}
+	if boolean(legend["rightSide"]) {
+		legendOptions["placement"] = "right"
+	}
+	for _, calc := range []string{"min", "max", "avg", "current", "total"} {
+		if boolean(legend[calc]) {
+			legendOptions["calcs"] = append(legendOptions["calcs"].([]any), reducer(calc))
+		}
+	}
+	tooltip, _ := p["tooltip"].(object)
+	tooltipOptions := object{"mode": "single", "sort": "none"}
+	if tooltip == nil || tooltip["shared"] == nil || boolean(tooltip["shared"]) {
+		tooltipOptions["mode"] = "multi"
+	}
+	switch v, _ := number(tooltip["sort"]); v {
+	case 1:
+		tooltipOptions["sort"] = "asc"
+	case 2:
+		tooltipOptions["sort"] = "desc"
+	}
+	p["options"] = object{"legend": legendOptions, "tooltip": tooltipOptions}
+
+	if aliasColors, ok := p["aliasColors"].(object); ok {
+		names := make([]string, 0, len(aliasColors))
+		for name := range aliasColors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			overrides = append(overrides, override(name, []property{{"color", object{"mode": "fixed", "fixedColor": aliasColors[name]}}}))
+		}
+	}
+	for _, so := range objects(p["seriesOverrides"]) {
+		if props := seriesOverrideProperties(p, so, yaxes); len(props) > 0 {
+			overrides = append(overrides, override(str(so["alias"]), props))
+		}
+	}
+	setOverrides(p, overrides)
+
+	deleteKeys(p, "aliasColors", "bars", "dashLength", "dashes", "decimals", "fill", "fillGradient", "grid",
+		"hiddenSeries", "legend", "lines", "linewidth", "nullPointMode", "percentage", "pointradius",
+		"points", "renderer", "seriesOverrides", "spaceLength", "stack", "steppedLine", "thresholds",
+		"timeRegions", "tooltip", "xaxis", "yaxes", "yaxis")
+	m.converted(p, "graph", "timeseries")
+}
+
+func dashStyle(p object) object {
+	dash, ok := number(p["dashLength"])
+	if !ok {
+		dash = 10
+	}
+	space, ok := number(p["spaceLength"])
+	if !ok {
+		space = 10
+	}
+	return object{"fill": "dash", "dash": []any{dash, space}}
+}
+
+// applyAxis moves the settings of a graph y axis into the field config.
+func applyAxis(defaults, custom, axis object) {
+	if unit := str(axis["format"]); unit != "" {
+		defaults["unit"] = unit
+	}
+	for _, key := range []string{"min", "max", "decimals"} {
+		if v, ok := number(axis[key]); ok {
+			defaults[key] = v
+		}
+	}
+	if label := str(axis["label"]); label != "" {
+		custom["axisLabel"] = label
+	}
+	if base, _ := number(axis["logBase"]); base > 1 {
+		custom["scaleDistribution"] = object{"type": "log", "log": base}
+	} else {
+		custom["scaleDistribution"] = object{"type": "linear"}
+	}
+	if show, ok := axis["show"].(bool); ok && !show {
+		custom["axisPlacement"] = "hidden"
+	}
+}
+
+// graphThresholds converts graph thresholds into threshold steps and the style to draw them with.
+func graphThresholds(thresholds []object) (object, object) {
+	sort.SliceStable(thresholds, func(i, j int) bool {
+		a, _ := number(thresholds[i]["value"])
+		b, _ := number(thresholds[j]["value"])
+		return a < b
+	})
+	steps := []any{object{"color": "transparent", "value": nil}}
+	fill, line := false, false
+	for _, t := range thresholds {
+		value, ok := number(t["value"])
+		if !ok {
+			continue
+		}
+		color := "red"
+		switch str(t["colorMode"]) {
+		case "warning":
+			color = "orange"
+		case "ok":
+			color = "green"
+		case "custom":
+			color = str(t["lineColor"])
+			if color == "" {
+				color = str(t["fillColor"])
+			}
+		}
+		fill = fill || boolean(t["fill"])
+		line = line || boolean(t["line"])
+		if t["op"] == "lt" {
+			// Values below the threshold are colored, so the color belongs to the previous step.
+			steps[len(steps)-1].(object)["color"] = color
+			steps = append(steps, object{"color": "transparent", "value": value})
+			continue
+		}
+		steps = append(steps, object{"color": color, "value": value})
+	}
+	mode := "off"
+	switch {
+	case fill && line:
+		mode = "line+area"
+	case fill:
+		mode = "area"
+	case line:
+		mode = "line"
+	}
+	return object{"mode": "absolute", "steps": steps}, object{"mode": mode}
+}
+
+// seriesOverrideProperties converts a graph series override into field config override properties.
+func seriesOverrideProperties(p, so object, yaxes []object) []property {
+	var props []property
+	if v, _ := number(so["yaxis"]); v == 2 {
+		props = append(props, property{"custom.axisPlacement", "right"})
+		if len(yaxes) > 1 {
+			if unit := str(yaxes[1]["format"]); unit != "" {
+				props = append(props, property{"unit", unit})
+			}
+		}
+	}
+	if color := str(so["color"]); color != "" {
+		props = append(props, property{"color", object{"mode": "fixed", "fixedColor": color}})
+	}
+	if v, ok := number(so["fill"]); ok {
+		props = append(props, property{"custom.fillOpacity", v * 10})
+	}
+	if v, ok := number(so["linewidth"]); ok {
+		props = append(props, property{"custom.lineWidth", v})
+	}
+	if b, ok := so["bars"].(bool); ok && b {
+		props = append(props, property{"custom.drawStyle", "bars"})
+	} else if b, ok := so["lines"].(bool); ok && b {
+		props = append(props, property{"custom.drawStyle", "line"})
+	}
+	if b, ok := so["points"].(bool); ok {
+		showPoints := "never"
+		if b {
+			showPoints = "always"
+		}
+		props = append(props, property{"custom.showPoints", showPoints})
+	}
+	if boolean(so["dashes"]) {
+		props = append(props, property{"custom.lineStyle", dashStyle(p)})
+	}
+	if b, ok := so["stack"].(bool); ok && !b {
+		props = append(props, property{"custom.stacking", object{"mode": "none", "group": "A"}})
+	}
+	if so["transform"] == "negative-Y" {
+		props = append(props, property{"custom.transform", "negative-Y"})
+	}
+	if b, ok := so["legend"].(bool); ok && !b {
+		props = append(props, property{"custom.hideFrom", object{"legend": true, "tooltip": false, "viz": false}})
+	}
+	return props
+}
+
+// tableTransforms maps the transforms of Angular tables to transformations.
+var tableTransforms = map[string]string{
+	"timeseries_to_rows":      "seriesToRows",
+	"timeseries_to_columns":   "seriesToColumns",
+	"timeseries_aggregations": "reduce",
+	"table":                   "merge",
+}
+
+// tableOld converts Angular table panels into React table panels.
+func (m *migrator) tableOld(p object) {
+	defaults, overrides := fieldConfig(p)
+	custom := child(defaults, "custom")
+	custom["align"] = "auto"
+	custom["cellOptions"] = object{"type": "auto"}
+
+	for _, style := range objects(p["styles"]) {
+		props := styleProperties(style)
+		pattern := str(style["pattern"])
+		if pattern == "/.*/" {
+			for _, prop := range props {
+				setProperty(defaults, prop.id, prop.value)
+			}
+			continue
+		}
+		if pattern != "" && len(props) > 0 {
+			overrides = append(overrides, override(pattern, props))
+		}
+	}
+	setOverrides(p, overrides)
+
+	if id, ok := tableTransforms[str(p["transform"])]; ok {
+		t := object{"id": id, "options": object{}}
+		if id == "reduce" {
+			var reducers []any
+			for _, c := range objects(p["columns"]) {
+				reducers = append(reducers, reducer(str(c["value"])))
+			}
+			t["options"] = object{"reducers": reducers}
+		}
+		transformations, _ := p["transformations"].([]any)
+		p["transformations"] = append([]any{t}, transformations...)
+	}
+	showHeader := p["showHeader"] == nil || boolean(p["showHeader"])
+	p["options"] = object{"showHeader": showHeader}
+
+	deleteKeys(p, "columns", "fontSize", "pageSize", "scroll", "showHeader", "sort", "styles", "transform")
+	m.converted(p, "table-old", "table")
+}
+
+// styleProperties converts a column style of an Angular table into field config properties.
+func styleProperties(style object) []property {
+	var props []property
+	if alias := str(style["alias"]); alias != "" {
+		props = append(props, property{"displayName", alias})
+	}
+	switch str(style["type"]) {
+	case "hidden":
+		props = append(props, property{"custom.hidden", true})
+	case "date":
+		if format := str(style["dateFormat"]); format != "" {
+			props = append(props, property{"unit", "time: " + format})
+		}
+	case "number":
+		if unit := str(style["unit"]); unit != "" {
+			props = append(props, property{"unit", unit})
+		}
+		if decimals, ok := number(style["decimals"]); ok {
+			props = append(props, property{"decimals", decimals})
+		}
+	}
+	if align := str(style["align"]); align != "" {
+		props = append(props, property{"custom.align", align})
+	}
+	switch str(style["colorMode"]) {
+	case "cell", "row":
+		props = append(props, property{"custom.cellOptions", object{"type": "color-background", "mode": "basic"}})
+	case "value":
+		props = append(props, property{"custom.cellOptions", object{"type": "color-text"}})
+	}
+	if thresholds := splitThresholds(style["thresholds"]); len(thresholds) > 0 {
+		colors, _ := style["colors"].([]any)
+		props = append(props, property{"thresholds", thresholdSteps(thresholds, colors)})
+	}
+	if mappings := legacyMappings(style); len(mappings) > 0 {
+		props = append(props, property{"mappings", mappings})
+	}
+	if boolean(style["link"]) {
+		url := strings.ReplaceAll(str(style["linkUrl"]), "$__cell", "${__value.text}")
+		props = append(props, property{"links", []any{object{
+			"title":       str(style["linkTooltip"]),
+			"url":         url,
+			"targetBlank": boolean(style["linkTargetBlank"]),
+		}}})
+	}
+	return props
+}
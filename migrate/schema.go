@@ -0,0 +1,670 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	gridColumnCount  = 24
+	gridCellHeight   = 30
+	gridCellVMargin  = 8
+	defaultRowHeight = 250
+	defaultPanelSpan = 4
+)
+
+// assignPanelIDs gives panels without an id the next free one.
+func (m *migrator) assignPanelIDs(d object) {
+	panels := allPanels(d)
+	maxID := maxPanelID(panels)
+	for _, p := range panels {
+		if id, ok := number(p["id"]); !ok || id == 0 {
+			maxID++
+			p["id"] = maxID
+		}
+	}
+}
+
+func maxPanelID(panels []object) int {
+	maxID := 0
+	for _, p := range panels {
+		if id, ok := number(p["id"]); ok && int(id) > maxID {
+			maxID = int(id)
+		}
+	}
+	return maxID
+}
+
+// gridThresholds moves the thresholds of graph panels from the grid into the thresholds list.
+func (m *migrator) gridThresholds(p object) {
+	grid, ok := p["grid"].(object)
+	if p["type"] != "graph" || !ok {
+		return
+	}
+	thresholds, _ := p["thresholds"].([]any)
+	t1, ok1 := number(grid["threshold1"])
+	t2, ok2 := number(grid["threshold2"])
+	op := "gt"
+	if ok1 && ok2 && t1 > t2 {
+		op = "lt"
+	}
+	if ok1 {
+		thresholds = append(thresholds, object{"value": t1, "colorMode": "custom", "op": op, "fill": true, "line": true, "fillColor": grid["threshold1Color"]})
+		if ok2 {
+			thresholds = append(thresholds, object{"value": t2, "colorMode": "custom", "op": op, "fill": true, "line": true, "fillColor": grid["threshold2Color"]})
+		}
+	}
+	if thresholds != nil {
+		p["thresholds"] = thresholds
+	}
+	for _, key := range []string{"threshold1", "threshold1Color", "threshold2", "threshold2Color", "thresholdLine"} {
+		delete(grid, key)
+	}
+}
+
+// sharedCrosshair replaces the sharedCrosshair flag by graphTooltip.
+func (m *migrator) sharedCrosshair(d object) {
+	if _, ok := d["sharedCrosshair"]; !ok {
+		return
+	}
+	if boolean(d["sharedCrosshair"]) {
+		d["graphTooltip"] = 1
+	} else {
+		d["graphTooltip"] = 0
+	}
+	delete(d, "sharedCrosshair")
+}
+
+// gridHeight converts a height in pixels, e.g. 250 or "250px", into grid units.
+func gridHeight(v any, def float64) int {
+	h, ok := number(v)
+	if s, isString := v.(string); isString {
+		h, ok = number(strings.TrimSuffix(strings.TrimSpace(s), "px"))
+	}
+	if !ok || h <= 0 {
+		h = def
+	}
+	return int(math.Ceil(h / (gridCellHeight + gridCellVMargin)))
+}
+
+// rowsToGrid replaces the legacy rows of 12 span units by panels positioned on the 24 column grid.
+// Row panels are added if any row is collapsed, repeated or shows its title.
+func (m *migrator) rowsToGrid(d object) {
+	rows := objects(d["rows"])
+	if rows == nil {
+		return
+	}
+	delete(d, "rows")
+	panels, _ := d["panels"].([]any)
+
+	showRows := false
+	for _, row := range rows {
+		if boolean(row["collapse"]) || boolean(row["showTitle"]) || str(row["repeat"]) != "" {
+			showRows = true
+		}
+	}
+	nextRowID := maxPanelID(allPanelsOfRows(rows)) + 1
+	y := 0
+	for _, row := range rows {
+		if row["repeatIteration"] != nil {
+			continue
+		}
+		rowHeight := gridHeight(row["height"], defaultRowHeight)
+		collapsed := boolean(row["collapse"])
+		var rowPanel object
+		if showRows {
+			rowPanel = object{
+				"id":        nextRowID,
+				"type":      "row",
+				"title":     str(row["title"]),
+				"collapsed": collapsed,
+				"panels":    []any{},
+				"gridPos":   object{"x": 0, "y": y, "w": gridColumnCount, "h": rowHeight},
+			}
+			if repeat := str(row["repeat"]); repeat != "" {
+				rowPanel["repeat"] = repeat
+			}
+			panels = append(panels, rowPanel)
+			nextRowID++
+			y++
+		}
+
+		// Panels fill the row from left to right and wrap to a new line when they do not fit.
+		x, lineY, lineHeight := 0, y, 0
+		for _, p := range objects(row["panels"]) {
+			span, ok := number(p["span"])
+			if !ok || span <= 0 {
+				span = defaultPanelSpan
+			}
+			if minSpan, ok := number(p["minSpan"]); ok {
+				p["minSpan"] = math.Min(gridColumnCount, minSpan*gridColumnCount/12)
+			}
+			w := int(math.Floor(span)) * gridColumnCount / 12
+			h := rowHeight
+			if p["height"] != nil {
+				h = gridHeight(p["height"], defaultRowHeight)
+			}
+			if x+w > gridColumnCount {
+				x, lineY, lineHeight = 0, lineY+lineHeight, 0
+			}
+			p["gridPos"] = object{"x": x, "y": lineY, "w": w, "h": h}
+			x += w
+			lineHeight = max(lineHeight, h)
+			delete(p, "span")
+			delete(p, "height")
+
+			if rowPanel != nil && collapsed {
+				rowPanel["panels"] = append(rowPanel["panels"].([]any), p)
+			} else {
+				panels = append(panels, p)
+			}
+		}
+		if rowPanel == nil || !collapsed {
+			y = max(y+rowHeight, lineY+lineHeight)
+		}
+	}
+	d["panels"] = panels
+}
+
+func allPanelsOfRows(rows []object) []object {
+	var out []object
+	for _, row := range rows {
+		out = append(out, objects(row["panels"])...)
+	}
+	return out
+}
+
+// minSpan replaces the minimal width of repeated panels by the maximum number of panels per row.
+func (m *migrator) minSpan(p object) {
+	minSpan, ok := number(p["minSpan"])
+	if !ok {
+		return
+	}
+	delete(p, "minSpan")
+	if minSpan <= 0 {
+		return
+	}
+	perRow := gridColumnCount / minSpan
+	for _, factor := range []float64{1, 2, 3, 4, 6, 8, 12, 24} {
+		if factor >= perRow {
+			p["maxPerRow"] = factor
+			return
+		}
+	}
+	p["maxPerRow"] = gridColumnCount
+}
+
+// panelLinks converts dashboard links of panels into URL links.
+func (m *migrator) panelLinks(p object) {
+	for _, link := range objects(p["links"]) {
+		u := str(link["url"])
+		if u == "" && str(link["dashboard"]) != "" {
+			u = "dashboard/db/" + slugify(str(link["dashboard"]))
+		}
+		if u == "" && str(link["dashUri"]) != "" {
+			u = "dashboard/" + str(link["dashUri"])
+		}
+		if u == "" {
+			u = "/"
+		}
+		if boolean(link["keepTime"]) {
+			u = appendQuery(u, "$__url_time_range")
+		}
+		if boolean(link["includeVars"]) {
+			u = appendQuery(u, "$__all_variables")
+		}
+		if params := str(link["params"]); params != "" {
+			u = appendQuery(u, params)
+		}
+		for k := range link {
+			if k != "title" && k != "targetBlank" {
+				delete(link, k)
+			}
+		}
+		link["url"] = u
+	}
+}
+
+func appendQuery(u, query string) string {
+	if strings.Contains(u, "?") {
+		return u + "&" + query
+	}
+	return u + "?" + query
+}
+
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 127 {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// dataLinks returns the data links of a panel in the places used before and after the field config was introduced.
+func dataLinks(p object) []object {
+	var links []object
+	if options, ok := p["options"].(object); ok {
+		links = append(links, objects(options["dataLinks"])...)
+		if fieldOptions, ok := options["fieldOptions"].(object); ok {
+			if defaults, ok := fieldOptions["defaults"].(object); ok {
+				links = append(links, objects(defaults["links"])...)
+			}
+		}
+	}
+	if fc, ok := p["fieldConfig"].(object); ok {
+		if defaults, ok := fc["defaults"].(object); ok {
+			links = append(links, objects(defaults["links"])...)
+		}
+	}
+	return links
+}
+
+func replaceInDataLinks(p object, r *strings.Replacer) {
+	for _, link := range dataLinks(p) {
+		if u, ok := link["url"].(string); ok {
+			link["url"] = r.Replace(u)
+		}
+	}
+}
+
+// dataLinkVariables renames the built-in variables of data links.
+func (m *migrator) dataLinkVariables(p object) {
+	replaceInDataLinks(p, strings.NewReplacer(
+		"$__field_name", "${__field.name}",
+		"__series_name", "__series.name",
+		"__value_time", "__value.time",
+	))
+}
+
+// dataLinkLabels moves the series labels of data links to the field.
+func (m *migrator) dataLinkLabels(p object) {
+	replaceInDataLinks(p, strings.NewReplacer("__series.labels", "__field.labels"))
+}
+
+// angularTable renames configured Angular table panels to table-old.
+func (m *migrator) angularTable(p object) {
+	if p["type"] == "table" && p["styles"] != nil && p["table"] != "table2" {
+		p["type"] = "table-old"
+	}
+}
+
+// textPanel renames the React text panel and moves the content of Angular text panels into options.
+func (m *migrator) textPanel(p object) {
+	if p["type"] == "text2" {
+		p["type"] = "text"
+	}
+	if p["type"] != "text" {
+		return
+	}
+	for _, key := range []string{"content", "mode"} {
+		if v, ok := p[key]; ok {
+			child(p, "options")[key] = v
+			delete(p, key)
+		}
+	}
+}
+
+// constantVariables turns visible constant variables into text box variables.
+func (m *migrator) constantVariables(d object) {
+	for _, v := range variables(d) {
+		if v["type"] != "constant" {
+			continue
+		}
+		if hide, _ := number(v["hide"]); hide == 0 || hide == 1 {
+			v["type"] = "textbox"
+		}
+		current := object{"selected": true, "text": str(v["query"]), "value": str(v["query"])}
+		v["current"] = current
+		v["options"] = []any{current}
+	}
+}
+
+// variableTags removes the tag support of variables.
+func (m *migrator) variableTags(d object) {
+	for _, v := range variables(d) {
+		for _, key := range []string{"tags", "tagsQuery", "tagValuesQuery", "useTags"} {
+			delete(v, key)
+		}
+	}
+}
+
+// queryVariableRefresh makes query variables refresh at least on dashboard load and drops their saved options.
+func (m *migrator) queryVariableRefresh(d object) {
+	for _, v := range variables(d) {
+		if v["type"] != "query" {
+			continue
+		}
+		if refresh, _ := number(v["refresh"]); refresh != 1 && refresh != 2 {
+			v["refresh"] = 1
+		}
+		if _, ok := v["options"]; ok {
+			v["options"] = []any{}
+		}
+	}
+}
+
+func variables(d object) []object {
+	templating, _ := d["templating"].(object)
+	return objects(templating["list"])
+}
+
+// valueMappingsAndTooltip upgrades value mappings to the grouped format and renames tooltipOptions.
+func (m *migrator) valueMappingsAndTooltip(p object) {
+	if fc, ok := p["fieldConfig"].(object); ok {
+		if defaults, ok := fc["defaults"].(object); ok && defaults["mappings"] != nil {
+			defaults["mappings"] = upgradeValueMappings(defaults["mappings"])
+		}
+		for _, o := range objects(fc["overrides"]) {
+			for _, prop := range objects(o["properties"]) {
+				if prop["id"] == "mappings" {
+					prop["value"] = upgradeValueMappings(prop["value"])
+				}
+			}
+		}
+	}
+	if options, ok := p["options"].(object); ok {
+		if tooltip, ok := options["tooltipOptions"]; ok {
+			options["tooltip"] = tooltip
+			delete(options, "tooltipOptions")
+		}
+	}
+}
+
+// upgradeValueMappings converts value mappings of the types 1 (value to text) and 2 (range to text).
+// Mappings already in the new format are kept.
+func upgradeValueMappings(v any) []any {
+	values := object{}
+	var out []any
+	index := 0
+	for _, mapping := range objects(v) {
+		if _, isNew := mapping["type"].(string); isNew {
+			out = append(out, mapping)
+			continue
+		}
+		result := object{"text": str(mapping["text"]), "index": index}
+		index++
+		switch t, _ := number(mapping["type"]); t {
+		case 1:
+			value := str(mapping["value"])
+			if value == "null" {
+				out = append(out, object{"type": "special", "options": object{"match": "null", "result": result}})
+			} else {
+				values[value] = result
+			}
+		case 2:
+			options := object{"result": result}
+			if from, ok := number(mapping["from"]); ok {
+				options["from"] = from
+			}
+			if to, ok := number(mapping["to"]); ok {
+				options["to"] = to
+			}
+			out = append(out, object{"type": "range", "options": options})
+		}
+	}
+	if len(values) > 0 {
+		out = append([]any{object{"type": "value", "options": values}}, out...)
+	}
+	if out == nil {
+		out = []any{}
+	}
+	return out
+}
+
+// labelsToFields merges the frames produced by the labelsToFields transformation, which no longer does so itself.
+func (m *migrator) labelsToFields(p object) {
+	transformations, _ := p["transformations"].([]any)
+	for i, t := range objects(transformations) {
+		if t["id"] == "labelsToFields" {
+			out := append([]any{}, transformations[:i+1]...)
+			out = append(out, object{"id": "merge", "options": object{}})
+			p["transformations"] = append(out, transformations[i+1:]...)
+			return
+		}
+	}
+}
+
+const (
+	mixedDatasource   = "-- Mixed --"
+	grafanaDatasource = "-- Grafana --"
+)
+
+func (m *migrator) defaultDatasource() *Datasource {
+	for i := range m.opts.Datasources {
+		if m.opts.Datasources[i].IsDefault {
+			return &m.opts.Datasources[i]
+		}
+	}
+	return nil
+}
+
+// datasourceRef converts a datasource name into a reference. References are returned unchanged.
+// A null or "default" name is returned as null if defaultAsNull is set, or as a reference to the
+// default datasource otherwise.
+func (m *migrator) datasourceRef(v any, defaultAsNull bool) any {
+	if _, ok := v.(object); ok {
+		return v
+	}
+	name := str(v)
+	if defaultAsNull && (v == nil || name == "default") {
+		return nil
+	}
+	switch {
+	case strings.HasPrefix(name, "$"):
+		return object{"uid": name}
+	case name == grafanaDatasource:
+		return object{"type": "grafana", "uid": name}
+	case name == mixedDatasource:
+		return object{"type": "datasource", "uid": name}
+	}
+	var ds *Datasource
+	if name == "" || name == "default" {
+		ds = m.defaultDatasource()
+	} else {
+		for i := range m.opts.Datasources {
+			if m.opts.Datasources[i].Name == name || m.opts.Datasources[i].UID == name {
+				ds = &m.opts.Datasources[i]
+				break
+			}
+		}
+	}
+	switch {
+	case ds != nil:
+		return object{"type": ds.Type, "uid": ds.UID}
+	case name != "" && name != "default":
+		return object{"uid": name}
+	}
+	return nil
+}
+
+// datasourceRefs replaces the datasource names of query variables by references.
+func (m *migrator) datasourceRefs(d object) {
+	for _, v := range variables(d) {
+		if v["type"] == "query" {
+			v["datasource"] = m.datasourceRef(v["datasource"], true)
+		}
+	}
+}
+
+// panelDatasourceRefs replaces the datasource names of panels and their queries by references.
+func (m *migrator) panelDatasourceRefs(p object) {
+	p["datasource"] = m.datasourceRef(p["datasource"], true)
+	if p["datasource"] == nil {
+		delete(p, "datasource")
+	}
+	for _, t := range objects(p["targets"]) {
+		if ref := m.datasourceRef(t["datasource"], true); ref != nil {
+			t["datasource"] = ref
+		}
+	}
+}
+
+// defaultDatasourceRefs makes annotations and query variables using the default datasource reference it.
+func (m *migrator) defaultDatasourceRefs(d object) {
+	if annotations, ok := d["annotations"].(object); ok {
+		for _, a := range objects(annotations["list"]) {
+			if ref := m.datasourceRef(a["datasource"], false); ref != nil {
+				a["datasource"] = ref
+			}
+		}
+	}
+	for _, v := range variables(d) {
+		if v["type"] != "query" {
+			continue
+		}
+		if ref := m.datasourceRef(v["datasource"], false); ref != nil {
+			v["datasource"] = ref
+		}
+	}
+}
+
+// panelDefaultDatasourceRefs makes panels and queries using the default datasource reference it.
+// Queries without a datasource get the one of their panel.
+func (m *migrator) panelDefaultDatasourceRefs(p object) {
+	targets := objects(p["targets"])
+	if p["type"] == "row" || len(targets) == 0 {
+		return
+	}
+	if p["datasource"] == nil {
+		if ref := m.datasourceRef(nil, false); ref != nil {
+			p["datasource"] = ref
+		}
+	}
+	panelRef, _ := p["datasource"].(object)
+	for _, t := range targets {
+		if ref, ok := t["datasource"].(object); ok && ref["uid"] != nil {
+			continue
+		}
+		switch {
+		case panelRef != nil && panelRef["uid"] == mixedDatasource:
+			if ref := m.datasourceRef(nil, false); ref != nil {
+				t["datasource"] = ref
+			}
+		case panelRef != nil:
+			ref := object{}
+			for k, v := range panelRef {
+				ref[k] = v
+			}
+			t["datasource"] = ref
+		}
+	}
+}
+
+// xAxisVisibility keeps the time axis of time series panels with hidden axes visible.
+func (m *migrator) xAxisVisibility(p object) {
+	if p["type"] != "timeseries" {
+		return
+	}
+	fc, _ := p["fieldConfig"].(object)
+	defaults, _ := fc["defaults"].(object)
+	custom, _ := defaults["custom"].(object)
+	if custom["axisPlacement"] != "hidden" {
+		return
+	}
+	overrides, _ := fc["overrides"].([]any)
+	fc["overrides"] = append(overrides, object{
+		"matcher":    object{"id": "byType", "options": "time"},
+		"properties": []any{object{"id": "custom.axisPlacement", "value": "auto"}},
+	})
+}
+
+// hiddenLegend replaces the hidden legend display mode by the showLegend option.
+func (m *migrator) hiddenLegend(p object) {
+	options, _ := p["options"].(object)
+	legend, ok := options["legend"].(object)
+	if !ok {
+		return
+	}
+	if legend["displayMode"] == "hidden" || legend["showLegend"] == false {
+		legend["displayMode"] = "list"
+		legend["showLegend"] = false
+	} else {
+		legend["showLegend"] = true
+	}
+}
+
+// tableCellOptions replaces the display mode of table cells by cell options.
+func (m *migrator) tableCellOptions(p object) {
+	if p["type"] != "table" {
+		return
+	}
+	fc, _ := p["fieldConfig"].(object)
+	if defaults, ok := fc["defaults"].(object); ok {
+		if custom, ok := defaults["custom"].(object); ok {
+			if mode, ok := custom["displayMode"].(string); ok {
+				custom["cellOptions"] = cellOptions(mode)
+				delete(custom, "displayMode")
+			}
+		}
+	}
+	for _, o := range objects(fc["overrides"]) {
+		for _, prop := range objects(o["properties"]) {
+			if prop["id"] == "custom.displayMode" {
+				prop["id"] = "custom.cellOptions"
+				prop["value"] = cellOptions(str(prop["value"]))
+			}
+		}
+	}
+}
+
+func cellOptions(displayMode string) object {
+	switch displayMode {
+	case "basic":
+		return object{"type": "gauge", "mode": "basic"}
+	case "gradient-gauge":
+		return object{"type": "gauge", "mode": "gradient"}
+	case "lcd-gauge":
+		return object{"type": "gauge", "mode": "lcd"}
+	case "color-background":
+		return object{"type": "color-background", "mode": "gradient"}
+	case "color-background-solid":
+		return object{"type": "color-background", "mode": "basic"}
+	}
+	return object{"type": displayMode}
+}
+
+// reducer maps the value names of Angular panels to the reducers of React panels.
+func reducer(valueName string) string {
+	switch valueName {
+	case "avg":
+		return "mean"
+	case "current":
+		return "lastNotNull"
+	case "total":
+		return "sum"
+	case "":
+		return "mean"
+	}
+	return valueName
+}
+
+func itoa(v any) string {
+	if f, ok := number(v); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return str(v)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// The dashboard model types decode JSON objects member by member so that nothing is lost
+// on a round trip:
+//   - members without a matching field are kept in the exported Extra map,
+//   - members whose value cannot be held by their field, either because the field would
+//     omit it (null, false, 0, "", [] or {}) or because the value has an unexpected type,
+//     are kept in an unexported map and re-emitted as long as the field stays empty.
+// Encoding emits the struct fields in declaration order followed by the kept members in
+// sorted order.
+
+type modelField struct {
+	index     int
+	omitEmpty bool
+	omitZero  bool
+}
+
+var modelFieldsCache sync.Map // map[reflect.Type]map[string]modelField
+
+// modelFields returns the JSON member names of the exported fields of the struct type t.
+func modelFields(t reflect.Type) map[string]modelField {
+	if fields, ok := modelFieldsCache.Load(t); ok {
+		return fields.(map[string]modelField)
+	}
+	fields := map[string]modelField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		optList := strings.Split(opts, ",")
+		fields[name] = modelField{
+			index:     i,
+			omitEmpty: slices.Contains(optList, "omitempty"),
+			omitZero:  slices.Contains(optList, "omitzero"),
+		}
+	}
+	modelFieldsCache.Store(t, fields)
+	return fields
+}
+
+// isOmitted reports whether encoding/json leaves the field out of the encoded object.
+func (f modelField) isOmitted(v reflect.Value) bool {
+	if f.omitZero {
+		if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+			if v.Kind() != reflect.Pointer || !v.IsNil() {
+				return z.IsZero()
+			}
+		}
+		if v.IsZero() {
+			return true
+		}
+	}
+	if !f.omitEmpty {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// unmarshalModel decodes the JSON object data into the struct pointed to by v, which must not
+// implement json.Unmarshaler itself. Unknown members are stored in extra and members that do not
+// fit their field in preserved.
+func unmarshalModel(data []byte, v any, extra, preserved *map[string]json.RawMessage) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v).Elem()
+	rv.SetZero()
+	*extra, *preserved = nil, nil
+	fields := modelFields(rv.Type())
+	for name, value := range members {
+		f, ok := fields[name]
+		if !ok {
+			if *extra == nil {
+				*extra = map[string]json.RawMessage{}
+			}
+			(*extra)[name] = value
+			continue
+		}
+		fv := rv.Field(f.index)
+		if err := json.Unmarshal(value, fv.Addr().Interface()); err != nil || f.isOmitted(fv) {
+			fv.SetZero()
+			if *preserved == nil {
+				*preserved = map[string]json.RawMessage{}
+			}
+			(*preserved)[name] = value
+		}
+	}
+	return nil
+}
+
+// marshalModel encodes the struct pointed to by v, which must not implement json.Marshaler itself,
+// followed by the members of preserved and extra that are not already set by a field.
+func marshalModel(v any, extra, preserved map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(extra) == 0 && len(preserved) == 0 {
+		return data, nil
+	}
+	var emitted map[string]json.RawMessage
+	if err := json.Unmarshal(data, &emitted); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(extra)+len(preserved))
+	kept := make(map[string]json.RawMessage, len(extra)+len(preserved))
+	for _, m := range []map[string]json.RawMessage{preserved, extra} {
+		for k, val := range m {
+			if _, ok := emitted[k]; ok {
+				continue
+			}
+			if _, ok := kept[k]; ok {
+				continue
+			}
+			kept[k] = val
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return data, nil
+	}
+	slices.Sort(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	for i, k := range keys {
+		if i > 0 || len(emitted) > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(kept[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package normalize brings dashboard models into a canonical form, so that dashboards kept in
+// version control only change when their content changes.
+package normalize
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/internal/jsonobj"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Rules selects the normalizations applied by Normalize.
+type Rules struct {
+	// StripVolatile removes the id, version and iteration of the dashboard, which change on every save
+	// or differ between Grafana instances.
+	StripVolatile bool
+	// StripKeys lists additional top level members of the dashboard to remove.
+	StripKeys []string
+	// StripPluginVersion removes the pluginVersion of panels, which changes when Grafana is upgraded.
+	StripPluginVersion bool
+	// SortPanels orders panels by their position on the grid, top to bottom and left to right.
+	SortPanels bool
+	// RemoveDefaults removes members of the dashboard, its panels, queries and variables that are
+	// null, empty or set to the value Grafana uses when they are missing.
+	RemoveDefaults bool
+}
+
+// DefaultRules returns the rules suited for keeping dashboards in version control.
+func DefaultRules() Rules {
+	return Rules{
+		StripVolatile:  true,
+		SortPanels:     true,
+		RemoveDefaults: true,
+	}
+}
+
+// Format is an output format of Marshal.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+var volatileKeys = []string{"id", "version", "iteration"}
+
+// Defaults of the members removed by Rules.RemoveDefaults, per kind of object.
+var (
+	dashboardDefaults = mustObject(`{"editable":true,"fiscalYearStartMonth":0,"graphTooltip":0,"hideControls":false,"liveNow":false,"style":"dark","timezone":"","weekStart":""}`)
+	panelDefaults     = mustObject(`{"description":"","hideTimeOverride":false,"interval":"","repeatDirection":"h","transparent":false}`)
+	targetDefaults    = mustObject(`{"hide":false,"interval":"","legendFormat":""}`)
+	variableDefaults  = mustObject(`{"description":"","hide":0,"includeAll":false,"label":"","multi":false,"regex":"","skipUrlSync":false,"sort":0,"tagValuesQuery":"","tagsQuery":"","useTags":false}`)
+)
+
+// Normalize returns a copy of the dashboard with the rules applied.
+func Normalize(d *sdk.Dashboard, rules Rules) (*sdk.Dashboard, error) {
+	obj, err := normalize(d, rules)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	out := &sdk.Dashboard{}
+	if err = json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Marshal normalizes the dashboard and encodes it in the given format. Object members are sorted by name
+// and indented by two spaces, so equal dashboards are always encoded to the same bytes.
+func Marshal(d *sdk.Dashboard, rules Rules, format Format) ([]byte, error) {
+	obj, err := normalize(d, rules)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(obj); err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON:
+		return buf.Bytes(), nil
+	case FormatYAML:
+		// JSON is valid YAML; decoding it keeps numbers and encoding sorts the keys.
+		var v any
+		if err = yaml.Unmarshal(buf.Bytes(), &v); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(v)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func normalize(d *sdk.Dashboard, rules Rules) (map[string]any, error) {
+	if d == nil {
+		return nil, errors.New("missing dashboard model")
+	}
+	obj, err := jsonobj.From(d)
+	if err != nil {
+		return nil, err
+	}
+	if rules.StripVolatile {
+		for _, key := range volatileKeys {
+			delete(obj, key)
+		}
+	}
+	for _, key := range rules.StripKeys {
+		delete(obj, key)
+	}
+	if rules.RemoveDefaults {
+		removeDefaults(obj, dashboardDefaults)
+	}
+	normalizePanels(obj, rules)
+	if templating, ok := obj["templating"].(map[string]any); ok && rules.RemoveDefaults {
+		for _, v := range objects(templating["list"]) {
+			removeDefaults(v, variableDefaults)
+		}
+	}
+	return obj, nil
+}
+
+// normalizePanels applies the rules to the panels of the dashboard or row obj.
+func normalizePanels(obj map[string]any, rules Rules) {
+	panels, ok := obj["panels"].([]any)
+	if !ok {
+		return
+	}
+	if rules.SortPanels {
+		sort.SliceStable(panels, func(i, j int) bool {
+			yi, xi := gridPos(panels[i])
+			yj, xj := gridPos(panels[j])
+			if yi != yj {
+				return yi < yj
+			}
+			return xi < xj
+		})
+	}
+	for _, p := range objects(panels) {
+		if rules.StripPluginVersion {
+			delete(p, "pluginVersion")
+		}
+		normalizePanels(p, rules)
+		if !rules.RemoveDefaults {
+			continue
+		}
+		for _, t := range objects(p["targets"]) {
+			removeDefaults(t, targetDefaults)
+		}
+		if fc, ok := p["fieldConfig"].(map[string]any); ok {
+			removeDefaults(fc, nil)
+		}
+		removeDefaults(p, panelDefaults)
+	}
+}
+
+func gridPos(panel any) (y, x float64) {
+	p, _ := panel.(map[string]any)
+	pos, _ := p["gridPos"].(map[string]any)
+	y, _ = number(pos["y"])
+	x, _ = number(pos["x"])
+	return y, x
+}
+
+// objects returns the JSON objects held by the array v.
+func objects(v any) []map[string]any {
+	list, _ := v.([]any)
+	out := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if obj, ok := item.(map[string]any); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// removeDefaults removes the members of obj that are null, empty or equal to their default.
+func removeDefaults(obj, defaults map[string]any) {
+	for k, v := range obj {
+		if def, ok := defaults[k]; jsonobj.IsEmpty(v) || (ok && equal(v, def)) {
+			delete(obj, k)
+		}
+	}
+}
+
+func equal(a, b any) bool {
+	if na, ok := number(a); ok {
+		nb, ok := number(b)
+		return ok && na == nb
+	}
+	return a == b
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func mustObject(s string) map[string]any {
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		panic(err)
+	}
+	return obj
+}
@@ -0,0 +1,155 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package normalize
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	sdk "go.openviz.dev/grafana-sdk"
+	"go.openviz.dev/grafana-sdk/internal/testutil"
+
+	"go.yaml.in/yaml/v2"
+)
+
+const exported = `{
+  "id": 12, "uid": "pg", "version": 7, "iteration": 1638177476998, "title": "Postgres", "editable": true, "graphTooltip": 0, "links": [],
+  "templating": {"list": [{"name": "ns", "type": "query", "hide": 0, "multi": false, "regex": "", "query": "label_values(ns)"}]},
+  "panels": [
+    {"id": 3, "type": "stat", "title": "Uptime", "gridPos": {"h": 8, "w": 12, "x": 12, "y": 1}, "transparent": false, "links": [], "pluginVersion": "8.2.0"},
+    {"id": 2, "type": "timeseries", "title": "CPU", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 1}, "fieldConfig": {"defaults": {}, "overrides": []}, "targets": [{"refId": "A", "expr": "up", "hide": false}]},
+    {"id": 1, "type": "row", "title": "General", "gridPos": {"h": 1, "w": 24, "x": 0, "y": 0}, "panels": []}
+  ]
+}`
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		want  string
+	}{
+		{
+			name:  "Default rules",
+			rules: DefaultRules(),
+			want: `{
+  "uid": "pg", "title": "Postgres",
+  "templating": {"list": [{"name": "ns", "type": "query", "query": "label_values(ns)"}]},
+  "panels": [
+    {"id": 1, "type": "row", "title": "General", "gridPos": {"h": 1, "w": 24, "x": 0, "y": 0}},
+    {"id": 2, "type": "timeseries", "title": "CPU", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 1}, "targets": [{"refId": "A", "expr": "up"}]},
+    {"id": 3, "type": "stat", "title": "Uptime", "gridPos": {"h": 8, "w": 12, "x": 12, "y": 1}, "pluginVersion": "8.2.0"}
+  ]
+}`,
+		},
+		{
+			name:  "Strip keys and plugin versions only",
+			rules: Rules{StripKeys: []string{"iteration", "links"}, StripPluginVersion: true},
+			want: `{
+  "id": 12, "uid": "pg", "version": 7, "title": "Postgres", "editable": true, "graphTooltip": 0,
+  "templating": {"list": [{"name": "ns", "type": "query", "hide": 0, "multi": false, "regex": "", "query": "label_values(ns)"}]},
+  "panels": [
+    {"id": 3, "type": "stat", "title": "Uptime", "gridPos": {"h": 8, "w": 12, "x": 12, "y": 1}, "transparent": false, "links": []},
+    {"id": 2, "type": "timeseries", "title": "CPU", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 1}, "fieldConfig": {"defaults": {}, "overrides": []}, "targets": [{"refId": "A", "expr": "up", "hide": false}]},
+    {"id": 1, "type": "row", "title": "General", "gridPos": {"h": 1, "w": 24, "x": 0, "y": 0}, "panels": []}
+  ]
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(testutil.MustUnmarshal[sdk.Dashboard](t, exported), tt.rules)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			gotJSON, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var a, b any
+			_ = json.Unmarshal(gotJSON, &a)
+			_ = json.Unmarshal([]byte(tt.want), &b)
+			if !reflect.DeepEqual(a, b) {
+				t.Errorf("Normalize() got = %s, want %s", gotJSON, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	// The same dashboard saved later by another Grafana, with members in a different order.
+	resaved := `{
+  "title": "Postgres", "uid": "pg", "version": 8, "id": 3, "iteration": 1,
+  "panels": [
+    {"type": "row", "id": 1, "title": "General", "gridPos": {"x": 0, "y": 0, "w": 24, "h": 1}},
+    {"title": "CPU", "id": 2, "type": "timeseries", "gridPos": {"x": 0, "y": 1, "w": 12, "h": 8}, "targets": [{"expr": "up", "refId": "A"}]},
+    {"gridPos": {"x": 12, "y": 1, "w": 12, "h": 8}, "type": "stat", "title": "Uptime", "id": 3, "pluginVersion": "8.2.0"}
+  ],
+  "templating": {"list": [{"query": "label_values(ns)", "type": "query", "name": "ns"}]}
+}`
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			a, err := Marshal(testutil.MustUnmarshal[sdk.Dashboard](t, exported), DefaultRules(), format)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			b, err := Marshal(testutil.MustUnmarshal[sdk.Dashboard](t, resaved), DefaultRules(), format)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(a, b) {
+				t.Errorf("Marshal() is not stable, got\n%s\nand\n%s", a, b)
+			}
+		})
+	}
+	if _, err := Marshal(testutil.MustUnmarshal[sdk.Dashboard](t, exported), DefaultRules(), "toml"); err == nil {
+		t.Errorf("Marshal() with unknown format error = nil")
+	}
+}
+
+func TestMarshal_Testdata(t *testing.T) {
+	data, err := os.ReadFile("../testdata/dashboard.yaml")
+	if err != nil {
+		t.Fatalf("failed to read json model, reason: %v", err)
+	}
+	d := testutil.MustUnmarshal[sdk.Dashboard](t, string(data))
+	out, err := Marshal(d, DefaultRules(), FormatJSON)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	again, err := Marshal(testutil.MustUnmarshal[sdk.Dashboard](t, string(out)), DefaultRules(), FormatJSON)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !bytes.Equal(out, again) {
+		t.Errorf("Marshal() is not idempotent")
+	}
+
+	out, err = Marshal(d, DefaultRules(), FormatYAML)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var model map[string]any
+	if err = yaml.Unmarshal(out, &model); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	if model["gnetId"] != 9628 || model["iteration"] != nil || model["title"] != d.Title {
+		t.Errorf("Marshal() yaml got gnetId = %v, iteration = %v, title = %v", model["gnetId"], model["iteration"], model["title"])
+	}
+}
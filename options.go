@@ -0,0 +1,322 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	auth               *AuthConfig
+	doer               Doer
+	httpClient         *http.Client
+	roundTripper       http.RoundTripper
+	tlsConfig          *tls.Config
+	caCerts            [][]byte
+	caCertFiles        []string
+	clientCert         []byte
+	clientKey          []byte
+	clientCertFile     string
+	clientKeyFile      string
+	insecureSkipVerify bool
+	timeout            time.Duration
+	proxyURL           string
+	userAgent          string
+	headers            http.Header
+	retry              *RetryPolicy
+}
+
+// WithAuth sets the credentials sent with every request.
+func WithAuth(auth *AuthConfig) Option {
+	return func(o *clientOptions) {
+		o.auth = auth
+	}
+}
+
+// WithBasicAuth authenticates every request with the given username and password.
+func WithBasicAuth(username, password string) Option {
+	return WithAuth(&AuthConfig{
+		BasicAuth: &BasicAuth{
+			Username: username,
+			Password: password,
+		},
+	})
+}
+
+// WithBearerToken authenticates every request with the given API key or service account token.
+func WithBearerToken(token string) Option {
+	return WithAuth(&AuthConfig{BearerToken: token})
+}
+
+// WithHTTPClient sends requests through a copy of hc. TLS, proxy and timeout options
+// are applied to the copy; TLS and proxy options require its Transport to be nil or an *http.Transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = hc
+	}
+}
+
+// WithRoundTripper sets the transport of the http.Client used by the client, e.g. a
+// tracing or recording RoundTripper chain.
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.roundTripper = rt
+	}
+}
+
+// WithDoer sends requests through d instead of an http.Client. TLS, proxy and timeout
+// options are passed to d if it implements ConfigurableDoer; otherwise they cannot be
+// combined with it and must be configured on d itself.
+func WithDoer(d Doer) Option {
+	return func(o *clientOptions) {
+		o.doer = d
+	}
+}
+
+// TransportConfig holds the TLS, proxy and timeout options passed to a ConfigurableDoer.
+// Zero fields were not set.
+type TransportConfig struct {
+	TLSConfig *tls.Config
+	Proxy     *url.URL
+	Timeout   time.Duration
+}
+
+// ConfigurableDoer is a Doer that applies the TLS, proxy and timeout options of NewClient itself.
+type ConfigurableDoer interface {
+	Doer
+	Configure(cfg TransportConfig) error
+}
+
+// WithTLSConfig sets the base TLS configuration. Other TLS options are applied on top of a clone of cfg.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *clientOptions) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithCACert adds PEM encoded CA certificates used to verify the Grafana server.
+func WithCACert(pem []byte) Option {
+	return func(o *clientOptions) {
+		o.caCerts = append(o.caCerts, pem)
+	}
+}
+
+// WithCACertFile adds a file of PEM encoded CA certificates used to verify the Grafana server.
+func WithCACertFile(path string) Option {
+	return func(o *clientOptions) {
+		o.caCertFiles = append(o.caCertFiles, path)
+	}
+}
+
+// WithClientCert sets the PEM encoded client certificate and key used for mTLS.
+func WithClientCert(certPEM, keyPEM []byte) Option {
+	return func(o *clientOptions) {
+		o.clientCert = certPEM
+		o.clientKey = keyPEM
+	}
+}
+
+// WithClientCertFile sets the files of the PEM encoded client certificate and key used for mTLS.
+func WithClientCertFile(certFile, keyFile string) Option {
+	return func(o *clientOptions) {
+		o.clientCertFile = certFile
+		o.clientKeyFile = keyFile
+	}
+}
+
+// WithInsecureSkipVerify disables verification of the server certificate.
+// It should only be used against development clusters.
+func WithInsecureSkipVerify() Option {
+	return func(o *clientOptions) {
+		o.insecureSkipVerify = true
+	}
+}
+
+// WithTimeout sets the timeout of every single HTTP request.
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithProxy routes requests through the given proxy URL.
+func WithProxy(proxyURL string) Option {
+	return func(o *clientOptions) {
+		o.proxyURL = proxyURL
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a static header sent with every request.
+func WithHeader(key, value string) Option {
+	return func(o *clientOptions) {
+		if o.headers == nil {
+			o.headers = http.Header{}
+		}
+		o.headers.Add(key, value)
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the client. See SetRetryPolicy.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(o *clientOptions) {
+		o.retry = p
+	}
+}
+
+// buildDoer returns the Doer described by the options.
+func (o *clientOptions) buildDoer() (Doer, error) {
+	tlsConfig, err := o.buildTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	var proxyURL *url.URL
+	if o.proxyURL != "" {
+		if proxyURL, err = url.Parse(o.proxyURL); err != nil {
+			return nil, fmt.Errorf("invalid proxy url, reason: %w", err)
+		}
+	}
+
+	if o.doer != nil {
+		if tlsConfig == nil && proxyURL == nil && o.timeout <= 0 {
+			return o.doer, nil
+		}
+		cd, ok := o.doer.(ConfigurableDoer)
+		if !ok {
+			return nil, errors.New("TLS, proxy and timeout options cannot be combined with a custom Doer")
+		}
+		if err = cd.Configure(TransportConfig{TLSConfig: tlsConfig, Proxy: proxyURL, Timeout: o.timeout}); err != nil {
+			return nil, fmt.Errorf("failed to configure Doer, reason: %w", err)
+		}
+		return o.doer, nil
+	}
+
+	hc := &http.Client{}
+	if o.httpClient != nil {
+		c := *o.httpClient
+		hc = &c
+	}
+	if o.roundTripper != nil {
+		hc.Transport = o.roundTripper
+	}
+	if tlsConfig != nil || proxyURL != nil {
+		var transport *http.Transport
+		switch rt := hc.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = rt.Clone()
+		default:
+			return nil, fmt.Errorf("TLS and proxy options require an *http.Transport, found %T", rt)
+		}
+		if tlsConfig != nil {
+			transport.TLSClientConfig = tlsConfig
+		}
+		if proxyURL != nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+		hc.Transport = transport
+	}
+	if o.timeout > 0 {
+		hc.Timeout = o.timeout
+	}
+	return hc, nil
+}
+
+func (o *clientOptions) hasTLS() bool {
+	return o.tlsConfig != nil ||
+		len(o.caCerts) > 0 ||
+		len(o.caCertFiles) > 0 ||
+		o.clientCert != nil ||
+		o.clientCertFile != "" ||
+		o.insecureSkipVerify
+}
+
+// buildTLSConfig returns the TLS configuration described by the options or nil if none was given.
+func (o *clientOptions) buildTLSConfig() (*tls.Config, error) {
+	if !o.hasTLS() {
+		return nil, nil
+	}
+	cfg := &tls.Config{}
+	if o.tlsConfig != nil {
+		cfg = o.tlsConfig.Clone()
+	}
+
+	caCerts := o.caCerts
+	for _, file := range o.caCertFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate, reason: %w", err)
+		}
+		caCerts = append(caCerts, data)
+	}
+	if len(caCerts) > 0 {
+		if cfg.RootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			cfg.RootCAs = pool
+		} else {
+			cfg.RootCAs = cfg.RootCAs.Clone()
+		}
+		for _, pem := range caCerts {
+			if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, errors.New("failed to parse CA certificate")
+			}
+		}
+	}
+
+	certPEM, keyPEM := o.clientCert, o.clientKey
+	if o.clientCertFile != "" {
+		var err error
+		if certPEM, err = os.ReadFile(o.clientCertFile); err != nil {
+			return nil, fmt.Errorf("failed to read client certificate, reason: %w", err)
+		}
+		if keyPEM, err = os.ReadFile(o.clientKeyFile); err != nil {
+			return nil, fmt.Errorf("failed to read client key, reason: %w", err)
+		}
+	}
+	if certPEM != nil {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate, reason: %w", err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+
+	if o.insecureSkipVerify {
+		cfg.InsecureSkipVerify = true
+	}
+	return cfg, nil
+}
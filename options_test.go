@@ -0,0 +1,103 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	var gotHeader http.Header
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"database":"ok"}`))
+	}))
+	defer srv.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantErr    bool
+		wantHeader map[string]string
+	}{
+		{
+			name:    "Untrusted server certificate",
+			wantErr: true,
+		},
+		{
+			name: "Custom CA with user agent and headers",
+			opts: []Option{
+				WithCACert(caPEM),
+				WithUserAgent("grafana-sdk-test"),
+				WithHeader("X-Tenant", "demo"),
+			},
+			wantHeader: map[string]string{
+				"User-Agent": "grafana-sdk-test",
+				"X-Tenant":   "demo",
+			},
+		},
+		{
+			name: "Insecure skip verify",
+			opts: []Option{WithInsecureSkipVerify()},
+		},
+		{
+			name:    "Invalid CA",
+			opts:    []Option{WithCACert([]byte("not a certificate"))},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotHeader = nil
+			c, err := NewClient(srv.URL, tt.opts...)
+			if err == nil {
+				_, err = c.GetHealth(context.TODO())
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetHealth() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for k, v := range tt.wantHeader {
+				if got := gotHeader.Get(k); got != v {
+					t.Errorf("header %s got = %v, want %v", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestNewClient_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"database":"ok"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err = c.GetHealth(context.TODO()); err == nil {
+		t.Errorf("GetHealth() error = nil, want timeout")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+const orgIDHeader = "X-Grafana-Org-Id"
+
+// WithOrgID returns a copy of the client that acts in the organization with the given id
+// by sending the X-Grafana-Org-Id header with every request. An id of 0 returns a client
+// acting in the default organization of the credentials.
+func (c *Client) WithOrgID(orgID int) *Client {
+	scoped := *c
+	scoped.orgID = orgID
+	return &scoped
+}
+
+// OrgID returns the organization id the client is scoped to or 0 if it is not scoped.
+func (c *Client) OrgID() int {
+	return c.orgID
+}
+
+// SwitchUserOrg switches the current organization of the signed in user.
+// It reflects POST /api/user/using/:orgId API call.
+func (c *Client) SwitchUserOrg(ctx context.Context, orgID int) (*GrafanaResponse, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, fmt.Sprintf("api/user/using/%v", orgID))
+	resp, err := c.do(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
+
+// ListOrgs returns all organizations. It requires Grafana admin permission.
+// It reflects GET /api/orgs API call.
+func (c *Client) ListOrgs(ctx context.Context) ([]Org, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/orgs")
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var orgs []Org
+	if err = decodeResponse(resp, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// GetOrgByName returns the organization with the given name.
+// It reflects GET /api/orgs/name/:orgName API call.
+func (c *Client) GetOrgByName(ctx context.Context, name string) (*Org, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/orgs/name", name)
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	org := &Org{}
+	if err = decodeResponse(resp, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// CreateOrg creates an organization. The id of the new organization is returned in GrafanaResponse.OrgID.
+// It reflects POST /api/orgs API call.
+func (c *Client) CreateOrg(ctx context.Context, org Org) (*GrafanaResponse, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/orgs")
+	resp, err := c.do(ctx, http.MethodPost, u.String(), Org{Name: org.Name})
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
+
+// UpdateOrg renames the organization identified by org.ID.
+// It reflects PUT /api/orgs/:orgId API call.
+func (c *Client) UpdateOrg(ctx context.Context, org Org) (*GrafanaResponse, error) {
+	if org.ID == nil {
+		return nil, fmt.Errorf("failed to update org, reason: missing org id")
+	}
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, fmt.Sprintf("api/orgs/%v", *org.ID))
+	resp, err := c.do(ctx, http.MethodPut, u.String(), Org{Name: org.Name})
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
+
+// DeleteOrg deletes the organization with the given id.
+// It reflects DELETE /api/orgs/:orgId API call.
+func (c *Client) DeleteOrg(ctx context.Context, orgID int) (*GrafanaResponse, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, fmt.Sprintf("api/orgs/%v", orgID))
+	resp, err := c.do(ctx, http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
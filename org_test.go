@@ -0,0 +1,180 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"gomodules.xyz/pointer"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	OrgID  string
+	Body   string
+}
+
+// newRecordingServer returns a server answering every request with the given status and body
+// and records the requests it receives.
+func newRecordingServer(t *testing.T, statusCode int, body string) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var reqs []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		reqs = append(reqs, recordedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			OrgID:  r.Header.Get(orgIDHeader),
+			Body:   string(data),
+		})
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reqs
+}
+
+func TestClient_WithOrgID(t *testing.T) {
+	srv, reqs := newRecordingServer(t, http.StatusOK, `{"id":2,"name":"tenant-a"}`)
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	scoped := c.WithOrgID(2)
+	if _, err = scoped.GetCurrentOrg(context.TODO()); err != nil {
+		t.Fatalf("GetCurrentOrg() error = %v", err)
+	}
+	if _, err = c.GetCurrentOrg(context.TODO()); err != nil {
+		t.Fatalf("GetCurrentOrg() error = %v", err)
+	}
+	if got := (*reqs)[0].OrgID; got != "2" {
+		t.Errorf("scoped client %s header got = %v, want 2", orgIDHeader, got)
+	}
+	if got := (*reqs)[1].OrgID; got != "" {
+		t.Errorf("unscoped client %s header got = %v, want none", orgIDHeader, got)
+	}
+	if c.OrgID() != 0 || scoped.OrgID() != 2 {
+		t.Errorf("OrgID() got = %v and %v, want 0 and 2", c.OrgID(), scoped.OrgID())
+	}
+}
+
+func TestClient_Orgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(c *Client) (any, error)
+		respBody   string
+		want       any
+		wantMethod string
+		wantPath   string
+		wantBody   string
+	}{
+		{
+			name: "List orgs",
+			call: func(c *Client) (any, error) {
+				return c.ListOrgs(context.TODO())
+			},
+			respBody:   `[{"id":1,"name":"Main Org."},{"id":2,"name":"tenant-a"}]`,
+			want:       []Org{{ID: pointer.IntP(1), Name: pointer.StringP("Main Org.")}, {ID: pointer.IntP(2), Name: pointer.StringP("tenant-a")}},
+			wantMethod: http.MethodGet,
+			wantPath:   "/api/orgs",
+		},
+		{
+			name: "Get org by name",
+			call: func(c *Client) (any, error) {
+				return c.GetOrgByName(context.TODO(), "tenant-a")
+			},
+			respBody:   `{"id":2,"name":"tenant-a"}`,
+			want:       &Org{ID: pointer.IntP(2), Name: pointer.StringP("tenant-a")},
+			wantMethod: http.MethodGet,
+			wantPath:   "/api/orgs/name/tenant-a",
+		},
+		{
+			name: "Create org",
+			call: func(c *Client) (any, error) {
+				return c.CreateOrg(context.TODO(), Org{Name: pointer.StringP("tenant-b")})
+			},
+			respBody:   `{"orgId":3,"message":"Organization created"}`,
+			want:       &GrafanaResponse{OrgID: pointer.IntP(3), Message: pointer.StringP("Organization created"), StatusCode: http.StatusOK},
+			wantMethod: http.MethodPost,
+			wantPath:   "/api/orgs",
+			wantBody:   `{"name":"tenant-b"}`,
+		},
+		{
+			name: "Update org",
+			call: func(c *Client) (any, error) {
+				return c.UpdateOrg(context.TODO(), Org{ID: pointer.IntP(3), Name: pointer.StringP("tenant-c")})
+			},
+			respBody:   `{"message":"Organization updated"}`,
+			want:       &GrafanaResponse{Message: pointer.StringP("Organization updated"), StatusCode: http.StatusOK},
+			wantMethod: http.MethodPut,
+			wantPath:   "/api/orgs/3",
+			wantBody:   `{"name":"tenant-c"}`,
+		},
+		{
+			name: "Delete org",
+			call: func(c *Client) (any, error) {
+				return c.DeleteOrg(context.TODO(), 3)
+			},
+			respBody:   `{"message":"Organization deleted"}`,
+			want:       &GrafanaResponse{Message: pointer.StringP("Organization deleted"), StatusCode: http.StatusOK},
+			wantMethod: http.MethodDelete,
+			wantPath:   "/api/orgs/3",
+		},
+		{
+			name: "Switch user org",
+			call: func(c *Client) (any, error) {
+				return c.SwitchUserOrg(context.TODO(), 2)
+			},
+			respBody:   `{"message":"Active organization changed"}`,
+			want:       &GrafanaResponse{Message: pointer.StringP("Active organization changed"), StatusCode: http.StatusOK},
+			wantMethod: http.MethodPost,
+			wantPath:   "/api/user/using/2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, reqs := newRecordingServer(t, http.StatusOK, tt.respBody)
+			c, err := NewClient(srv.URL, WithAuth(validAuth))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			got, err := tt.call(c)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("got = %s, want %s", gotJSON, wantJSON)
+			}
+			req := (*reqs)[0]
+			if req.Method != tt.wantMethod || req.Path != tt.wantPath {
+				t.Errorf("request got = %s %s, want %s %s", req.Method, req.Path, tt.wantMethod, tt.wantPath)
+			}
+			if req.Body != tt.wantBody {
+				t.Errorf("request body got = %s, want %s", req.Body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+)
+
+// PermissionLevel is the access a permission grants.
+type PermissionLevel int
+
+const (
+	PermissionView  PermissionLevel = 1
+	PermissionEdit  PermissionLevel = 2
+	PermissionAdmin PermissionLevel = 4
+)
+
+func (l PermissionLevel) String() string {
+	switch l {
+	case PermissionView:
+		return "View"
+	case PermissionEdit:
+		return "Edit"
+	case PermissionAdmin:
+		return "Admin"
+	}
+	return strconv.Itoa(int(l))
+}
+
+// Basic roles that permissions can be granted to.
+const (
+	RoleViewer = "Viewer"
+	RoleEditor = "Editor"
+	RoleAdmin  = "Admin"
+)
+
+// PermissionItem is a permission of a dashboard or folder granted to exactly one of a user,
+// a team or a basic role, as described in the doc
+// https://grafana.com/docs/grafana/latest/developers/http_api/dashboard_permissions/
+type PermissionItem struct {
+	UserID     int             `json:"userId,omitempty"`
+	TeamID     int             `json:"teamId,omitempty"`
+	Role       string          `json:"role,omitempty"`
+	Permission PermissionLevel `json:"permission"`
+	// The following fields are only set by Grafana when permissions are read.
+	UserLogin      string `json:"userLogin,omitempty"`
+	Team           string `json:"team,omitempty"`
+	PermissionName string `json:"permissionName,omitempty"`
+	// Inherited is set for permissions a dashboard inherits from its folder.
+	Inherited bool `json:"inherited,omitempty"`
+}
+
+// PermissionChanges is the difference between two sets of permissions.
+type PermissionChanges struct {
+	Add    []PermissionItem
+	Update []PermissionItem
+	Remove []PermissionItem
+}
+
+type permissionGrant struct {
+	UserID     int             `json:"userId,omitempty"`
+	TeamID     int             `json:"teamId,omitempty"`
+	Role       string          `json:"role,omitempty"`
+	Permission PermissionLevel `json:"permission"`
+}
+
+type updatePermissionsRequest struct {
+	Items []permissionGrant `json:"items"`
+}
+
+// subject identifies who a permission is granted to.
+func (p PermissionItem) subject() string {
+	switch {
+	case p.UserID != 0:
+		return "user:" + strconv.Itoa(p.UserID)
+	case p.TeamID != 0:
+		return "team:" + strconv.Itoa(p.TeamID)
+	default:
+		return "role:" + p.Role
+	}
+}
+
+func (p PermissionItem) hasSingleSubject() bool {
+	n := 0
+	if p.UserID != 0 {
+		n++
+	}
+	if p.TeamID != 0 {
+		n++
+	}
+	if p.Role != "" {
+		n++
+	}
+	return n == 1
+}
+
+// IsEmpty reports whether there are no changes.
+func (c PermissionChanges) IsEmpty() bool {
+	return len(c.Add) == 0 && len(c.Update) == 0 && len(c.Remove) == 0
+}
+
+// DiffPermissions returns the changes needed to turn the current permissions into the desired ones.
+// Inherited permissions cannot be changed on a dashboard and are ignored. If desired grants several
+// levels to the same user, team or role, the highest level wins.
+func DiffPermissions(current, desired []PermissionItem) PermissionChanges {
+	cur := permissionsBySubject(current)
+	want := permissionsBySubject(desired)
+
+	var changes PermissionChanges
+	for _, key := range sortedKeys(want) {
+		w := want[key]
+		c, ok := cur[key]
+		switch {
+		case !ok:
+			changes.Add = append(changes.Add, w)
+		case c.Permission != w.Permission:
+			changes.Update = append(changes.Update, w)
+		}
+	}
+	for _, key := range sortedKeys(cur) {
+		if _, ok := want[key]; !ok {
+			changes.Remove = append(changes.Remove, cur[key])
+		}
+	}
+	return changes
+}
+
+func permissionsBySubject(items []PermissionItem) map[string]PermissionItem {
+	out := map[string]PermissionItem{}
+	for _, item := range items {
+		if item.Inherited {
+			continue
+		}
+		key := item.subject()
+		if existing, ok := out[key]; ok && existing.Permission >= item.Permission {
+			continue
+		}
+		out[key] = item
+	}
+	return out
+}
+
+func sortedKeys(m map[string]PermissionItem) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetDashboardPermissions returns the permissions of the dashboard with the given uid.
+// It reflects GET /api/dashboards/uid/:uid/permissions API call.
+func (c *Client) GetDashboardPermissions(ctx context.Context, uid string) ([]PermissionItem, error) {
+	return c.getPermissions(ctx, path.Join("api/dashboards/uid", uid, "permissions"))
+}
+
+// SetDashboardPermissions replaces all permissions of the dashboard with the given uid
+// that are not inherited from its folder. If items grant several levels to the same user, team
+// or role, the highest level wins.
+// It reflects POST /api/dashboards/uid/:uid/permissions API call.
+func (c *Client) SetDashboardPermissions(ctx context.Context, uid string, items []PermissionItem) (*GrafanaResponse, error) {
+	return c.setPermissions(ctx, path.Join("api/dashboards/uid", uid, "permissions"), items)
+}
+
+// EnsureDashboardPermissions updates the permissions of the dashboard with the given uid to the
+// desired ones, if they differ, and returns the changes that were applied.
+func (c *Client) EnsureDashboardPermissions(ctx context.Context, uid string, desired []PermissionItem) (PermissionChanges, error) {
+	return c.ensurePermissions(ctx, path.Join("api/dashboards/uid", uid, "permissions"), desired)
+}
+
+// GetFolderPermissions returns the permissions of the folder with the given uid.
+// It reflects GET /api/folders/:uid/permissions API call.
+func (c *Client) GetFolderPermissions(ctx context.Context, uid string) ([]PermissionItem, error) {
+	return c.getPermissions(ctx, path.Join("api/folders", uid, "permissions"))
+}
+
+// SetFolderPermissions replaces all permissions of the folder with the given uid. If items grant
+// several levels to the same user, team or role, the highest level wins.
+// It reflects POST /api/folders/:uid/permissions API call.
+func (c *Client) SetFolderPermissions(ctx context.Context, uid string, items []PermissionItem) (*GrafanaResponse, error) {
+	return c.setPermissions(ctx, path.Join("api/folders", uid, "permissions"), items)
+}
+
+// EnsureFolderPermissions updates the permissions of the folder with the given uid to the
+// desired ones, if they differ, and returns the changes that were applied.
+func (c *Client) EnsureFolderPermissions(ctx context.Context, uid string, desired []PermissionItem) (PermissionChanges, error) {
+	return c.ensurePermissions(ctx, path.Join("api/folders", uid, "permissions"), desired)
+}
+
+func (c *Client) getPermissions(ctx context.Context, apiPath string) ([]PermissionItem, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, apiPath)
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var items []PermissionItem
+	if err = decodeResponse(resp, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *Client) setPermissions(ctx context.Context, apiPath string, items []PermissionItem) (*GrafanaResponse, error) {
+	for _, item := range items {
+		if !item.Inherited && !item.hasSingleSubject() {
+			return nil, fmt.Errorf("failed to set permissions, reason: permission %+v must be granted to exactly one user, team or role", item)
+		}
+	}
+	// Send the permissions DiffPermissions compares with: one per subject, with the highest level.
+	bySubject := permissionsBySubject(items)
+	req := updatePermissionsRequest{Items: []permissionGrant{}}
+	for _, key := range sortedKeys(bySubject) {
+		item := bySubject[key]
+		req.Items = append(req.Items, permissionGrant{
+			UserID:     item.UserID,
+			TeamID:     item.TeamID,
+			Role:       item.Role,
+			Permission: item.Permission,
+		})
+	}
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, apiPath)
+	resp, err := c.do(ctx, http.MethodPost, u.String(), req)
+	if err != nil {
+		return nil, err
+	}
+	return grafanaResponse(resp)
+}
+
+func (c *Client) ensurePermissions(ctx context.Context, apiPath string, desired []PermissionItem) (PermissionChanges, error) {
+	current, err := c.getPermissions(ctx, apiPath)
+	if err != nil {
+		return PermissionChanges{}, err
+	}
+	changes := DiffPermissions(current, desired)
+	if changes.IsEmpty() {
+		return changes, nil
+	}
+	if _, err = c.setPermissions(ctx, apiPath, desired); err != nil {
+		return PermissionChanges{}, err
+	}
+	return changes, nil
+}
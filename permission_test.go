@@ -0,0 +1,192 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDiffPermissions(t *testing.T) {
+	current := []PermissionItem{
+		{Role: RoleViewer, Permission: PermissionView},
+		{Role: RoleEditor, Permission: PermissionEdit},
+		{TeamID: 3, Team: "dba", Permission: PermissionView},
+		{UserID: 1, UserLogin: "admin", Permission: PermissionAdmin, Inherited: true},
+	}
+	tests := []struct {
+		name    string
+		desired []PermissionItem
+		want    PermissionChanges
+	}{
+		{
+			name: "Unchanged",
+			desired: []PermissionItem{
+				{TeamID: 3, Permission: PermissionView},
+				{Role: RoleEditor, Permission: PermissionEdit},
+				{Role: RoleViewer, Permission: PermissionView},
+			},
+		},
+		{
+			name: "Add, update and remove",
+			desired: []PermissionItem{
+				{Role: RoleEditor, Permission: PermissionEdit},
+				{TeamID: 3, Permission: PermissionAdmin},
+				{UserID: 7, Permission: PermissionEdit},
+			},
+			want: PermissionChanges{
+				Add:    []PermissionItem{{UserID: 7, Permission: PermissionEdit}},
+				Update: []PermissionItem{{TeamID: 3, Permission: PermissionAdmin}},
+				Remove: []PermissionItem{{Role: RoleViewer, Permission: PermissionView}},
+			},
+		},
+		{
+			name: "Highest duplicate wins",
+			desired: []PermissionItem{
+				{Role: RoleEditor, Permission: PermissionEdit},
+				{Role: RoleViewer, Permission: PermissionEdit},
+				{Role: RoleViewer, Permission: PermissionView},
+				{TeamID: 3, Permission: PermissionView},
+			},
+			want: PermissionChanges{
+				Update: []PermissionItem{{Role: RoleViewer, Permission: PermissionEdit}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffPermissions(current, tt.desired)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffPermissions() got = %+v, want %+v", got, tt.want)
+			}
+			if got.IsEmpty() != (tt.name == "Unchanged") {
+				t.Errorf("IsEmpty() got = %v", got.IsEmpty())
+			}
+		})
+	}
+}
+
+func TestClient_EnsurePermissions(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		ensure    func(c *Client, desired []PermissionItem) (PermissionChanges, error)
+		desired   []PermissionItem
+		wantPost  bool
+		wantItems []permissionGrant
+		wantErr   bool
+	}{
+		{
+			name: "Dashboard unchanged",
+			path: "/api/dashboards/uid/pg/permissions",
+			ensure: func(c *Client, desired []PermissionItem) (PermissionChanges, error) {
+				return c.EnsureDashboardPermissions(context.TODO(), "pg", desired)
+			},
+			desired: []PermissionItem{{Role: RoleViewer, Permission: PermissionView}},
+		},
+		{
+			name: "Dashboard changed",
+			path: "/api/dashboards/uid/pg/permissions",
+			ensure: func(c *Client, desired []PermissionItem) (PermissionChanges, error) {
+				return c.EnsureDashboardPermissions(context.TODO(), "pg", desired)
+			},
+			desired:  []PermissionItem{{Role: RoleViewer, Permission: PermissionView}, {TeamID: 2, Permission: PermissionEdit}},
+			wantPost: true,
+		},
+		{
+			name: "Folder changed",
+			path: "/api/folders/db/permissions",
+			ensure: func(c *Client, desired []PermissionItem) (PermissionChanges, error) {
+				return c.EnsureFolderPermissions(context.TODO(), "db", desired)
+			},
+			desired:  []PermissionItem{{UserID: 4, Permission: PermissionAdmin}},
+			wantPost: true,
+		},
+		{
+			name: "Duplicate subjects",
+			path: "/api/folders/db/permissions",
+			ensure: func(c *Client, desired []PermissionItem) (PermissionChanges, error) {
+				return c.EnsureFolderPermissions(context.TODO(), "db", desired)
+			},
+			desired: []PermissionItem{
+				{TeamID: 2, Permission: PermissionView},
+				{Role: RoleViewer, Permission: PermissionView},
+				{TeamID: 2, Permission: PermissionEdit},
+				{TeamID: 2, Permission: PermissionView},
+			},
+			wantPost: true,
+			wantItems: []permissionGrant{
+				{Role: RoleViewer, Permission: PermissionView},
+				{TeamID: 2, Permission: PermissionEdit},
+			},
+		},
+		{
+			name: "Invalid subject",
+			path: "/api/folders/db/permissions",
+			ensure: func(c *Client, desired []PermissionItem) (PermissionChanges, error) {
+				return c.EnsureFolderPermissions(context.TODO(), "db", desired)
+			},
+			desired: []PermissionItem{{UserID: 4, Role: RoleEditor, Permission: PermissionAdmin}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted *updatePermissionsRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if r.Method == http.MethodPost {
+					posted = &updatePermissionsRequest{}
+					_ = json.NewDecoder(r.Body).Decode(posted)
+					_, _ = w.Write([]byte(`{"message":"Permissions updated"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`[{"role":"Viewer","permission":1,"permissionName":"View"},{"userId":1,"userLogin":"admin","permission":4,"inherited":true}]`))
+			}))
+			defer srv.Close()
+			c, err := NewClient(srv.URL, WithAuth(validAuth))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			changes, err := tt.ensure(c, tt.desired)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ensure permissions error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (posted != nil) != tt.wantPost {
+				t.Fatalf("permissions posted = %v, want %v", posted != nil, tt.wantPost)
+			}
+			if changes.IsEmpty() == tt.wantPost && !tt.wantErr {
+				t.Errorf("Ensure permissions changes = %+v", changes)
+			}
+			if tt.wantItems != nil {
+				if !reflect.DeepEqual(posted.Items, tt.wantItems) {
+					t.Errorf("posted items got = %+v, want %+v", posted.Items, tt.wantItems)
+				}
+			} else if posted != nil && len(posted.Items) != len(tt.desired) {
+				t.Errorf("posted items got = %+v, want %d items", posted.Items, len(tt.desired))
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+const backendElasticsearch = "elasticsearch"
+
+// Elasticsearch queries the API of an Elasticsearch datasource.
+type Elasticsearch struct {
+	client *sdk.Client
+	uid    string
+}
+
+// NewElasticsearch returns a client for the Elasticsearch datasource with the given uid.
+func NewElasticsearch(c *sdk.Client, uid string) *Elasticsearch {
+	return &Elasticsearch{client: c, uid: uid}
+}
+
+// Search runs the search request body, e.g. a map holding "query" and "size", against index,
+// which may be a pattern like "logs-*", and returns the raw search response. Grafana only
+// proxies POST requests to _msearch, so the search is sent as a multi search of one request.
+// It reflects POST /_msearch Elasticsearch API call.
+func (e *Elasticsearch) Search(ctx context.Context, index string, body any) (json.RawMessage, error) {
+	header, err := json.Marshal(map[string]string{"index": index})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode elasticsearch search, reason: %w", err)
+	}
+	search, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode elasticsearch search, reason: %w", err)
+	}
+	data := append(append(append(header, '\n'), search...), '\n')
+	resp, err := e.client.DatasourceProxy(ctx, e.uid, sdk.ProxyRequest{
+		Method:      http.MethodPost,
+		Path:        "_msearch",
+		Body:        data,
+		ContentType: "application/x-ndjson",
+	})
+	if err != nil {
+		return nil, elasticsearchError(err)
+	}
+
+	var out struct {
+		Responses []json.RawMessage `json:"responses"`
+	}
+	if err = json.Unmarshal(resp.Body, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode elasticsearch response, reason: %w", err)
+	}
+	if len(out.Responses) != 1 {
+		return nil, fmt.Errorf("failed to decode elasticsearch response, reason: expected 1 response, got %d", len(out.Responses))
+	}
+	// The responses of a multi search carry their own errors.
+	var failed errorBody
+	if json.Unmarshal(out.Responses[0], &failed) == nil && failed.Error.Reason != "" {
+		return nil, &BackendError{Backend: backendElasticsearch, Type: failed.Error.Type, Message: failed.Error.Reason}
+	}
+	return out.Responses[0], nil
+}
+
+// errorBody is the body of an Elasticsearch error.
+type errorBody struct {
+	Error struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// elasticsearchError turns the error body of Elasticsearch into a *BackendError.
+func elasticsearchError(err error) error {
+	var apiErr *sdk.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	var body errorBody
+	if json.Unmarshal(apiErr.Body, &body) != nil || body.Error.Reason == "" {
+		return err
+	}
+	return &BackendError{Backend: backendElasticsearch, Type: body.Error.Type, Message: body.Error.Reason, Err: err}
+}
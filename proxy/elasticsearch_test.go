@@ -0,0 +1,77 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestElasticsearch_Search(t *testing.T) {
+	c, reqs := newProxyServer(t, http.StatusOK, `{"took":3,"responses":[{"hits":{"total":{"value":1}},"status":200}]}`)
+	got, err := NewElasticsearch(c, "E1").Search(context.TODO(), "logs-*", map[string]any{"size": 0})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if string(got) != `{"hits":{"total":{"value":1}},"status":200}` {
+		t.Errorf("Search() got = %s", got)
+	}
+	want := recordedRequest{
+		Method:      http.MethodPost,
+		Path:        "/api/datasources/proxy/uid/E1/_msearch",
+		Query:       url.Values{},
+		OrgID:       "3",
+		ContentType: "application/x-ndjson",
+		Body:        "{\"index\":\"logs-*\"}\n{\"size\":0}\n",
+	}
+	if !reflect.DeepEqual((*reqs)[0], want) {
+		t.Errorf("Search() sent = %+v, want %+v", (*reqs)[0], want)
+	}
+}
+
+func TestElasticsearch_SearchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{
+			name:   "Failed search",
+			status: http.StatusOK,
+			body:   `{"responses":[{"error":{"type":"index_not_found_exception","reason":"no such index [missing]"},"status":404}]}`,
+		},
+		{
+			name:   "Failed request",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"type":"index_not_found_exception","reason":"no such index [missing]"},"status":400}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newProxyServer(t, tt.status, tt.body)
+			_, err := NewElasticsearch(c, "E1").Search(context.TODO(), "missing", map[string]any{})
+			var backendErr *BackendError
+			if !errors.As(err, &backendErr) || backendErr.Type != "index_not_found_exception" {
+				t.Errorf("Search() error = %v, want backend error", err)
+			}
+		})
+	}
+}
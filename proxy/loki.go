@@ -0,0 +1,86 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+const backendLoki = "loki"
+
+// Loki queries the HTTP API of a Loki datasource.
+type Loki struct {
+	client *sdk.Client
+	uid    string
+}
+
+// NewLoki returns a client for the Loki datasource with the given uid.
+func NewLoki(c *sdk.Client, uid string) *Loki {
+	return &Loki{client: c, uid: uid}
+}
+
+func (l *Loki) get(ctx context.Context, apiPath string, query url.Values, out any) ([]string, error) {
+	return getData(ctx, l.client, l.uid, backendLoki, apiPath, query, out)
+}
+
+// QueryRange evaluates query from start to end. Log queries return at most limit lines, newest
+// first, and Loki's default limit if limit is not positive; use Streams on the result. Metric
+// queries return a matrix.
+// It reflects GET /loki/api/v1/query_range Loki API call.
+func (l *Loki) QueryRange(ctx context.Context, query string, start, end time.Time, limit int) (*QueryResult, error) {
+	q := url.Values{"query": {query}}
+	setTime(q, "start", start)
+	setTime(q, "end", end)
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	res := &QueryResult{}
+	warnings, err := l.get(ctx, "loki/api/v1/query_range", q, res)
+	if err != nil {
+		return nil, err
+	}
+	res.Warnings = warnings
+	return res, nil
+}
+
+// Labels returns the label names of the streams from start to end.
+// It reflects GET /loki/api/v1/labels Loki API call.
+func (l *Loki) Labels(ctx context.Context, start, end time.Time) ([]string, error) {
+	q := url.Values{}
+	setTime(q, "start", start)
+	setTime(q, "end", end)
+	var out []string
+	_, err := l.get(ctx, "loki/api/v1/labels", q, &out)
+	return out, err
+}
+
+// LabelValues returns the values of the label with the given name from start to end.
+// It reflects GET /loki/api/v1/label/:name/values Loki API call.
+func (l *Loki) LabelValues(ctx context.Context, label string, start, end time.Time) ([]string, error) {
+	q := url.Values{}
+	setTime(q, "start", start)
+	setTime(q, "end", end)
+	var out []string
+	_, err := l.get(ctx, path.Join("loki/api/v1/label", label, "values"), q, &out)
+	return out, err
+}
@@ -0,0 +1,98 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoki_QueryRange(t *testing.T) {
+	c, reqs := newProxyServer(t, http.StatusOK, `{"status":"success","data":{"resultType":"streams","result":[
+  {"stream":{"app":"api"},"values":[["1704067200000000001","GET /healthz 200"],["1704067199000000000","started",{"trace_id":"abc"}]]}
+]}}`)
+	res, err := NewLoki(c, "L1").QueryRange(context.TODO(), `{app="api"}`, start, end, 100)
+	if err != nil {
+		t.Fatalf("QueryRange() error = %v", err)
+	}
+	want := recordedRequest{
+		Method: http.MethodGet,
+		Path:   "/api/datasources/proxy/uid/L1/loki/api/v1/query_range",
+		Query: url.Values{
+			"query": {`{app="api"}`},
+			"start": {"2024-01-01T00:00:00Z"},
+			"end":   {"2024-01-01T01:00:00Z"},
+			"limit": {"100"},
+		},
+		OrgID: "3",
+	}
+	if !reflect.DeepEqual((*reqs)[0], want) {
+		t.Errorf("QueryRange() sent = %+v, want %+v", (*reqs)[0], want)
+	}
+	streams, err := res.Streams()
+	if err != nil {
+		t.Fatalf("Streams() error = %v", err)
+	}
+	wantStreams := []Stream{{
+		Labels: map[string]string{"app": "api"},
+		Entries: []LogEntry{
+			{Time: start.Add(time.Nanosecond), Line: "GET /healthz 200"},
+			{Time: start.Add(-time.Second), Line: "started"},
+		},
+	}}
+	if !reflect.DeepEqual(streams, wantStreams) {
+		t.Errorf("Streams() got = %+v, want %+v", streams, wantStreams)
+	}
+}
+
+func TestLoki_Labels(t *testing.T) {
+	c, reqs := newProxyServer(t, http.StatusOK, `{"status":"success","data":["app","namespace"]}`)
+	l := NewLoki(c, "L1")
+	labels, err := l.Labels(context.TODO(), start, time.Time{})
+	if err != nil {
+		t.Fatalf("Labels() error = %v", err)
+	}
+	if !reflect.DeepEqual(labels, []string{"app", "namespace"}) {
+		t.Errorf("Labels() got = %v", labels)
+	}
+	if _, err = l.LabelValues(context.TODO(), "app", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("LabelValues() error = %v", err)
+	}
+	if got := []string{(*reqs)[0].Path, (*reqs)[1].Path}; !reflect.DeepEqual(got, []string{
+		"/api/datasources/proxy/uid/L1/loki/api/v1/labels",
+		"/api/datasources/proxy/uid/L1/loki/api/v1/label/app/values",
+	}) {
+		t.Errorf("paths = %v", got)
+	}
+	if got := (*reqs)[0].Query; !reflect.DeepEqual(got, url.Values{"start": {"2024-01-01T00:00:00Z"}}) {
+		t.Errorf("Labels() sent query = %v", got)
+	}
+}
+
+func TestLoki_PlainTextError(t *testing.T) {
+	c, _ := newProxyServer(t, http.StatusBadRequest, "parse error at line 1, col 6: syntax error: unexpected IDENTIFIER\n")
+	_, err := NewLoki(c, "L1").QueryRange(context.TODO(), "{app=", start, end, 0)
+	var backendErr *BackendError
+	if !errors.As(err, &backendErr) || backendErr.Message != "parse error at line 1, col 6: syntax error: unexpected IDENTIFIER" {
+		t.Errorf("QueryRange() error = %v, want backend error", err)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+const backendPrometheus = "prometheus"
+
+// Prometheus queries the HTTP API of a Prometheus datasource.
+type Prometheus struct {
+	client *sdk.Client
+	uid    string
+}
+
+// NewPrometheus returns a client for the Prometheus datasource with the given uid.
+func NewPrometheus(c *sdk.Client, uid string) *Prometheus {
+	return &Prometheus{client: c, uid: uid}
+}
+
+// MetricMetadata is the metadata of a metric.
+type MetricMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+func (p *Prometheus) get(ctx context.Context, apiPath string, query url.Values, out any) ([]string, error) {
+	return getData(ctx, p.client, p.uid, backendPrometheus, apiPath, query, out)
+}
+
+// Query evaluates an instant query at ts, or now if ts is zero.
+// It reflects GET /api/v1/query Prometheus API call.
+func (p *Prometheus) Query(ctx context.Context, query string, ts time.Time) (*QueryResult, error) {
+	q := url.Values{"query": {query}}
+	setTime(q, "time", ts)
+	res := &QueryResult{}
+	warnings, err := p.get(ctx, "api/v1/query", q, res)
+	if err != nil {
+		return nil, err
+	}
+	res.Warnings = warnings
+	return res, nil
+}
+
+// QueryRange evaluates query from start to end with the given step.
+// It reflects GET /api/v1/query_range Prometheus API call.
+func (p *Prometheus) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (*QueryResult, error) {
+	q := url.Values{
+		"query": {query},
+		"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+	}
+	setTime(q, "start", start)
+	setTime(q, "end", end)
+	res := &QueryResult{}
+	warnings, err := p.get(ctx, "api/v1/query_range", q, res)
+	if err != nil {
+		return nil, err
+	}
+	res.Warnings = warnings
+	return res, nil
+}
+
+// Series returns the label sets of the series matching any of the matchers, e.g. `up{job="api"}`.
+// It reflects GET /api/v1/series Prometheus API call.
+func (p *Prometheus) Series(ctx context.Context, matchers []string, start, end time.Time) ([]map[string]string, error) {
+	var out []map[string]string
+	_, err := p.get(ctx, "api/v1/series", matchQuery(matchers, start, end), &out)
+	return out, err
+}
+
+// Labels returns the label names of the series matching any of the matchers, or of all series if
+// there are none.
+// It reflects GET /api/v1/labels Prometheus API call.
+func (p *Prometheus) Labels(ctx context.Context, matchers []string, start, end time.Time) ([]string, error) {
+	var out []string
+	_, err := p.get(ctx, "api/v1/labels", matchQuery(matchers, start, end), &out)
+	return out, err
+}
+
+// LabelValues returns the values of the label with the given name of the series matching any of
+// the matchers, or of all series if there are none.
+// It reflects GET /api/v1/label/:name/values Prometheus API call.
+func (p *Prometheus) LabelValues(ctx context.Context, label string, matchers []string, start, end time.Time) ([]string, error) {
+	var out []string
+	_, err := p.get(ctx, path.Join("api/v1/label", label, "values"), matchQuery(matchers, start, end), &out)
+	return out, err
+}
+
+// Metadata returns the metadata of the given metric, or of all metrics if it is empty, keyed by
+// metric name.
+// It reflects GET /api/v1/metadata Prometheus API call.
+func (p *Prometheus) Metadata(ctx context.Context, metric string) (map[string][]MetricMetadata, error) {
+	q := url.Values{}
+	if metric != "" {
+		q.Set("metric", metric)
+	}
+	var out map[string][]MetricMetadata
+	_, err := p.get(ctx, "api/v1/metadata", q, &out)
+	return out, err
+}
+
+func matchQuery(matchers []string, start, end time.Time) url.Values {
+	q := url.Values{}
+	for _, m := range matchers {
+		q.Add("match[]", m)
+	}
+	setTime(q, "start", start)
+	setTime(q, "end", end)
+	return q
+}
@@ -0,0 +1,210 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+type recordedRequest struct {
+	Method      string
+	Path        string
+	Query       url.Values
+	OrgID       string
+	ContentType string
+	Body        string
+}
+
+// newProxyServer returns a client of a server answering every request with status and body,
+// and the requests it received.
+func newProxyServer(t *testing.T, status int, body string) (*sdk.Client, *[]recordedRequest) {
+	t.Helper()
+	var reqs []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		reqs = append(reqs, recordedRequest{
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Query:       r.URL.Query(),
+			OrgID:       r.Header.Get("X-Grafana-Org-Id"),
+			ContentType: r.Header.Get("Content-Type"),
+			Body:        string(data),
+		})
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := sdk.NewClient(srv.URL, sdk.WithAuth(&sdk.AuthConfig{BearerToken: "token"}))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c.WithOrgID(3), &reqs
+}
+
+var (
+	start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end   = start.Add(time.Hour)
+)
+
+func TestPrometheus_Query(t *testing.T) {
+	c, reqs := newProxyServer(t, http.StatusOK, `{"status":"success","warnings":["slow"],"data":{"resultType":"vector","result":[
+  {"metric":{"__name__":"up","job":"api"},"value":[1704067200.5,"1"]}
+]}}`)
+	res, err := NewPrometheus(c, "P1").Query(context.TODO(), `up{job="api"}`, start)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want := recordedRequest{
+		Method: http.MethodGet,
+		Path:   "/api/datasources/proxy/uid/P1/api/v1/query",
+		Query:  url.Values{"query": {`up{job="api"}`}, "time": {"2024-01-01T00:00:00Z"}},
+		OrgID:  "3",
+	}
+	if !reflect.DeepEqual((*reqs)[0], want) {
+		t.Errorf("Query() sent = %+v, want %+v", (*reqs)[0], want)
+	}
+	samples, err := res.Vector()
+	if err != nil {
+		t.Fatalf("Vector() error = %v", err)
+	}
+	wantSamples := []Sample{{
+		Metric: map[string]string{"__name__": "up", "job": "api"},
+		Value:  Point{Time: start.Add(500 * time.Millisecond), Value: "1"},
+	}}
+	if !reflect.DeepEqual(samples, wantSamples) || !reflect.DeepEqual(res.Warnings, []string{"slow"}) {
+		t.Errorf("Query() got = %+v, warnings %v", samples, res.Warnings)
+	}
+	if _, err = res.Matrix(); err == nil {
+		t.Errorf("Matrix() of a vector expected error")
+	}
+}
+
+func TestPrometheus_QueryRange(t *testing.T) {
+	c, reqs := newProxyServer(t, http.StatusOK, `{"status":"success","data":{"resultType":"matrix","result":[
+  {"metric":{"job":"api"},"values":[[1704067200,"1"],[1704067230,"NaN"]]}
+]}}`)
+	res, err := NewPrometheus(c, "P1").QueryRange(context.TODO(), "up", start, end, 30*time.Second)
+	if err != nil {
+		t.Fatalf("QueryRange() error = %v", err)
+	}
+	if got := (*reqs)[0].Query; got.Get("step") != "30" || got.Get("start") != "2024-01-01T00:00:00Z" || got.Get("end") != "2024-01-01T01:00:00Z" {
+		t.Errorf("QueryRange() sent query = %v", got)
+	}
+	series, err := res.Matrix()
+	if err != nil {
+		t.Fatalf("Matrix() error = %v", err)
+	}
+	if len(series) != 1 || len(series[0].Values) != 2 || !series[0].Values[1].Time.Equal(start.Add(30*time.Second)) {
+		t.Fatalf("Matrix() got = %+v", series)
+	}
+	if v, err := series[0].Values[1].Float(); err != nil || !math.IsNaN(v) {
+		t.Errorf("Float() got = %v, %v, want NaN", v, err)
+	}
+}
+
+func TestPrometheus_Metadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		call     func(p *Prometheus) (any, error)
+		wantPath string
+		wantQ    url.Values
+		want     any
+	}{
+		{
+			name: "Series",
+			body: `{"status":"success","data":[{"__name__":"up","job":"api"}]}`,
+			call: func(p *Prometheus) (any, error) {
+				return p.Series(context.TODO(), []string{"up", "process_start_time_seconds"}, start, end)
+			},
+			wantPath: "/api/datasources/proxy/uid/P1/api/v1/series",
+			wantQ:    url.Values{"match[]": {"up", "process_start_time_seconds"}, "start": {"2024-01-01T00:00:00Z"}, "end": {"2024-01-01T01:00:00Z"}},
+			want:     []map[string]string{{"__name__": "up", "job": "api"}},
+		},
+		{
+			name:     "Labels",
+			body:     `{"status":"success","data":["__name__","job"]}`,
+			call:     func(p *Prometheus) (any, error) { return p.Labels(context.TODO(), nil, time.Time{}, time.Time{}) },
+			wantPath: "/api/datasources/proxy/uid/P1/api/v1/labels",
+			wantQ:    url.Values{},
+			want:     []string{"__name__", "job"},
+		},
+		{
+			name: "Label values",
+			body: `{"status":"success","data":["api","db"]}`,
+			call: func(p *Prometheus) (any, error) {
+				return p.LabelValues(context.TODO(), "job", []string{`up`}, time.Time{}, time.Time{})
+			},
+			wantPath: "/api/datasources/proxy/uid/P1/api/v1/label/job/values",
+			wantQ:    url.Values{"match[]": {"up"}},
+			want:     []string{"api", "db"},
+		},
+		{
+			name:     "Metadata",
+			body:     `{"status":"success","data":{"up":[{"type":"gauge","help":"Target is up.","unit":""}]}}`,
+			call:     func(p *Prometheus) (any, error) { return p.Metadata(context.TODO(), "up") },
+			wantPath: "/api/datasources/proxy/uid/P1/api/v1/metadata",
+			wantQ:    url.Values{"metric": {"up"}},
+			want:     map[string][]MetricMetadata{"up": {{Type: "gauge", Help: "Target is up."}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, reqs := newProxyServer(t, http.StatusOK, tt.body)
+			got, err := tt.call(NewPrometheus(c, "P1"))
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+			if r := (*reqs)[0]; r.Path != tt.wantPath || !reflect.DeepEqual(r.Query, tt.wantQ) {
+				t.Errorf("sent = %+v, want path %v and query %v", r, tt.wantPath, tt.wantQ)
+			}
+		})
+	}
+}
+
+func TestPrometheus_Errors(t *testing.T) {
+	c, _ := newProxyServer(t, http.StatusBadRequest, `{"status":"error","errorType":"bad_data","error":"invalid parameter \"query\": 1:3: parse error"}`)
+	_, err := NewPrometheus(c, "P1").Query(context.TODO(), "up{", time.Time{})
+	var backendErr *BackendError
+	if !errors.As(err, &backendErr) || backendErr.Type != "bad_data" {
+		t.Fatalf("Query() error = %v, want bad_data backend error", err)
+	}
+	var apiErr *sdk.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Query() error = %v, want it to wrap the API error", err)
+	}
+
+	c, _ = newProxyServer(t, http.StatusNotFound, `{"message":"Data source not found"}`)
+	_, err = NewPrometheus(c, "missing").Labels(context.TODO(), nil, time.Time{}, time.Time{})
+	if !sdk.IsNotFound(err) || errors.As(err, &backendErr) {
+		t.Errorf("Labels() error = %v, want Grafana not found error", err)
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proxy queries the backends of Prometheus, Loki and Elasticsearch datasources through
+// the datasource proxy of Grafana, so only Grafana credentials are needed. The clients reuse the
+// authentication and organization of the given *sdk.Client, e.g. one returned by WithOrgID.
+//
+//	prom := proxy.NewPrometheus(client, "prometheus-uid")
+//	res, err := prom.Query(ctx, `up{job="grafana"}`, time.Time{})
+//	samples, err := res.Vector()
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	sdk "go.openviz.dev/grafana-sdk"
+)
+
+// BackendError is returned if the backend of a datasource answers a request with an error.
+type BackendError struct {
+	// Backend is the kind of backend, e.g. "prometheus".
+	Backend string
+	// Type is the type of the error, if the backend reports one, e.g. "bad_data".
+	Type    string
+	Message string
+	// Err is the *sdk.APIError of the response.
+	Err error
+}
+
+func (e *BackendError) Error() string {
+	if e.Type != "" {
+		return fmt.Sprintf("%s request failed, reason: %s: %s", e.Backend, e.Type, e.Message)
+	}
+	return fmt.Sprintf("%s request failed, reason: %s", e.Backend, e.Message)
+}
+
+func (e *BackendError) Unwrap() error {
+	return e.Err
+}
+
+// apiResponse is the envelope of the Prometheus and Loki HTTP APIs.
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Warnings  []string        `json:"warnings,omitempty"`
+}
+
+// getData sends a GET request for apiPath through the proxy and decodes the data of the
+// Prometheus style response into out. It returns the warnings of the response.
+func getData(ctx context.Context, c *sdk.Client, uid, backend, apiPath string, query url.Values, out any) ([]string, error) {
+	resp, err := c.DatasourceProxy(ctx, uid, sdk.ProxyRequest{Path: apiPath, Query: query})
+	if err != nil {
+		return nil, backendError(backend, err)
+	}
+	var r apiResponse
+	if err = json.Unmarshal(resp.Body, &r); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response, reason: %w", backend, err)
+	}
+	if r.Status != "success" {
+		return nil, &BackendError{Backend: backend, Type: r.ErrorType, Message: r.Error}
+	}
+	if err = json.Unmarshal(r.Data, out); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response, reason: %w", backend, err)
+	}
+	return r.Warnings, nil
+}
+
+// backendError turns an *sdk.APIError carrying the error of the backend into a *BackendError.
+// Errors of Grafana itself, e.g. an unknown datasource, are returned as is.
+func backendError(backend string, err error) error {
+	var apiErr *sdk.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	var r apiResponse
+	if json.Unmarshal(apiErr.Body, &r) == nil {
+		if r.Error == "" {
+			return err
+		}
+		return &BackendError{Backend: backend, Type: r.ErrorType, Message: r.Error, Err: err}
+	}
+	// Loki answers with plain text errors.
+	if msg := string(bytes.TrimSpace(apiErr.Body)); msg != "" {
+		return &BackendError{Backend: backend, Message: msg, Err: err}
+	}
+	return err
+}
+
+func setTime(q url.Values, key string, t time.Time) {
+	if !t.IsZero() {
+		q.Set(key, t.Format(time.RFC3339Nano))
+	}
+}
+
+// QueryResult is the result of a Prometheus or Loki query.
+type QueryResult struct {
+	// ResultType is one of "vector", "matrix", "scalar", "string" and, for Loki, "streams".
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+	Warnings   []string        `json:"-"`
+}
+
+func (r *QueryResult) decode(resultType string, out any) error {
+	if r.ResultType != resultType {
+		return fmt.Errorf("result is a %s, not a %s", r.ResultType, resultType)
+	}
+	return json.Unmarshal(r.Result, out)
+}
+
+// Vector returns the samples of an instant query.
+func (r *QueryResult) Vector() ([]Sample, error) {
+	var out []Sample
+	return out, r.decode("vector", &out)
+}
+
+// Matrix returns the series of a range query.
+func (r *QueryResult) Matrix() ([]Series, error) {
+	var out []Series
+	return out, r.decode("matrix", &out)
+}
+
+// Scalar returns the value of a scalar query.
+func (r *QueryResult) Scalar() (Point, error) {
+	var out Point
+	return out, r.decode("scalar", &out)
+}
+
+// Streams returns the log streams of a Loki log query.
+func (r *QueryResult) Streams() ([]Stream, error) {
+	var out []Stream
+	return out, r.decode("streams", &out)
+}
+
+// Point is a value at a point in time. Value is kept as returned, e.g. "NaN" or "+Inf".
+type Point struct {
+	Time  time.Time
+	Value string
+}
+
+// Float returns Value as a float.
+func (p Point) Float() (float64, error) {
+	return strconv.ParseFloat(p.Value, 64)
+}
+
+// UnmarshalJSON decodes a [<unix seconds>, "<value>"] pair.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("point must be a [time, value] pair, got %s", data)
+	}
+	var ts float64
+	if err := json.Unmarshal(pair[0], &ts); err != nil {
+		return fmt.Errorf("invalid point time: %w", err)
+	}
+	if err := json.Unmarshal(pair[1], &p.Value); err != nil {
+		return fmt.Errorf("invalid point value: %w", err)
+	}
+	sec := int64(ts)
+	p.Time = time.Unix(sec, int64((ts-float64(sec))*1e3+0.5)*int64(time.Millisecond)).UTC()
+	return nil
+}
+
+// Sample is an element of an instant vector.
+type Sample struct {
+	Metric map[string]string `json:"metric"`
+	Value  Point             `json:"value"`
+}
+
+// Series is an element of a range vector.
+type Series struct {
+	Metric map[string]string `json:"metric"`
+	Values []Point           `json:"values"`
+}
+
+// Stream is a Loki log stream.
+type Stream struct {
+	Labels  map[string]string `json:"stream"`
+	Entries []LogEntry        `json:"values"`
+}
+
+// LogEntry is a log line of a Loki stream.
+type LogEntry struct {
+	Time time.Time
+	Line string
+}
+
+// UnmarshalJSON decodes a ["<unix nanoseconds>", "<line>"] pair; structured metadata is ignored.
+func (e *LogEntry) UnmarshalJSON(data []byte) error {
+	var entry []json.RawMessage
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+	if len(entry) < 2 {
+		return fmt.Errorf("log entry must be a [time, line] pair, got %s", data)
+	}
+	var ts string
+	if err := json.Unmarshal(entry[0], &ts); err != nil {
+		return fmt.Errorf("invalid log entry time: %w", err)
+	}
+	ns, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid log entry time: %w", err)
+	}
+	if err = json.Unmarshal(entry[1], &e.Line); err != nil {
+		return fmt.Errorf("invalid log entry line: %w", err)
+	}
+	e.Time = time.Unix(0, ns).UTC()
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package restyadapter sends grafana_sdk.Client requests through a resty client.
+package restyadapter
+
+import (
+	"io"
+	"net/http"
+
+	sdk "go.openviz.dev/grafana-sdk"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Doer implements grafana_sdk.Doer on top of a resty client.
+type Doer struct {
+	client *resty.Client
+}
+
+var _ sdk.ConfigurableDoer = &Doer{}
+
+// New returns a Doer sending requests through client. If client is nil a new resty client is created.
+func New(client *resty.Client) *Doer {
+	if client == nil {
+		client = resty.New()
+	}
+	return &Doer{client: client}
+}
+
+// WithClient returns an Option that sends the requests of a grafana_sdk.Client through client.
+// TLS, proxy and timeout options of grafana_sdk.NewClient are applied to client.
+func WithClient(client *resty.Client) sdk.Option {
+	return sdk.WithDoer(New(client))
+}
+
+// NewClient creates a grafana_sdk.Client like grafana_sdk.NewClient(hostURL, auth, client) did
+// before the client was decoupled from resty: it authenticates with auth and sends requests through
+// client, or a new resty client if it is nil. opts are applied after auth and client.
+func NewClient(hostURL string, auth *sdk.AuthConfig, client *resty.Client, opts ...sdk.Option) (*sdk.Client, error) {
+	return sdk.NewClient(hostURL, append([]sdk.Option{sdk.WithAuth(auth), WithClient(client)}, opts...)...)
+}
+
+// Configure applies the TLS, proxy and timeout options of grafana_sdk.NewClient to the resty client.
+func (d *Doer) Configure(cfg sdk.TransportConfig) error {
+	if cfg.TLSConfig != nil || cfg.Proxy != nil {
+		// resty only logs these errors, so check the transport first
+		if _, err := d.client.Transport(); err != nil {
+			return err
+		}
+	}
+	if cfg.TLSConfig != nil {
+		d.client.SetTLSClientConfig(cfg.TLSConfig)
+	}
+	if cfg.Proxy != nil {
+		d.client.SetProxy(cfg.Proxy.String())
+	}
+	if cfg.Timeout > 0 {
+		d.client.SetTimeout(cfg.Timeout)
+	}
+	return nil
+}
+
+// Do sends req through the resty client. The body of the returned response must be closed by the caller.
+func (d *Doer) Do(req *http.Request) (*http.Response, error) {
+	r := d.client.R().
+		SetContext(req.Context()).
+		SetHeaderMultiValues(req.Header).
+		SetDoNotParseResponse(true)
+	if req.Body != nil {
+		defer req.Body.Close() // nolint:errcheck
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		r.SetBody(body)
+	}
+	resp, err := r.Execute(req.Method, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	return resp.RawResponse, nil
+}
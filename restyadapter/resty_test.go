@@ -0,0 +1,164 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restyadapter
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "go.openviz.dev/grafana-sdk"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestWithClient(t *testing.T) {
+	var gotBody map[string]any
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotBody)
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Data source not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"message":"Datasource added"}`))
+	}))
+	defer srv.Close()
+
+	c, err := sdk.NewClient(srv.URL, sdk.WithBearerToken("token"), WithClient(resty.New()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	got, err := c.CreateDatasource(context.TODO(), &sdk.Datasource{Name: "prom", Type: "prometheus"})
+	if err != nil {
+		t.Fatalf("CreateDatasource() error = %v", err)
+	}
+	if got.Message == nil || *got.Message != "Datasource added" {
+		t.Errorf("CreateDatasource() got = %v, want Datasource added", got.Message)
+	}
+	if gotAuth != "Bearer token" {
+		t.Errorf("Authorization header got = %v, want Bearer token", gotAuth)
+	}
+	if gotBody["name"] != "prom" {
+		t.Errorf("request body got = %v, want name prom", gotBody)
+	}
+
+	_, err = c.DeleteDatasource(context.TODO(), 1)
+	if !errors.Is(err, sdk.ErrNotFound) {
+		t.Errorf("DeleteDatasource() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	var gotUser, gotAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _, _ = r.BasicAuth()
+		gotAgent = r.Header.Get("User-Agent")
+		_, _ = w.Write([]byte(`{"id":1,"name":"Main Org."}`))
+	}))
+	defer srv.Close()
+
+	auth := &sdk.AuthConfig{BasicAuth: &sdk.BasicAuth{Username: "admin", Password: "admin"}}
+	for _, rc := range []*resty.Client{nil, resty.New()} {
+		c, err := NewClient(srv.URL, auth, rc, sdk.WithUserAgent("test-agent"))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		org, err := c.GetCurrentOrg(context.TODO())
+		if err != nil {
+			t.Fatalf("GetCurrentOrg() error = %v", err)
+		}
+		if *org.Name != "Main Org." || gotUser != "admin" || gotAgent != "test-agent" {
+			t.Errorf("GetCurrentOrg() got = %v, user %v, user agent %v", *org.Name, gotUser, gotAgent)
+		}
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"database":"ok"}`))
+	}))
+	defer tlsSrv.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsSrv.Certificate().Raw})
+
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		_, _ = w.Write([]byte(`{"database":"ok"}`))
+	}))
+	defer proxy.Close()
+
+	tests := []struct {
+		name        string
+		url         string
+		opts        []sdk.Option
+		wantErr     bool
+		wantProxied string
+		wantTimeout time.Duration
+	}{
+		{
+			name:    "Untrusted server certificate",
+			url:     tlsSrv.URL,
+			wantErr: true,
+		},
+		{
+			name:        "Custom CA with timeout",
+			url:         tlsSrv.URL,
+			opts:        []sdk.Option{sdk.WithCACert(caPEM), sdk.WithTimeout(time.Minute)},
+			wantTimeout: time.Minute,
+		},
+		{
+			name: "Insecure skip verify",
+			url:  tlsSrv.URL,
+			opts: []sdk.Option{sdk.WithInsecureSkipVerify()},
+		},
+		{
+			name:        "Proxy",
+			url:         "http://grafana.invalid",
+			opts:        []sdk.Option{sdk.WithProxy(proxy.URL)},
+			wantProxied: "grafana.invalid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxiedHost = ""
+			rc := resty.New()
+			c, err := NewClient(tt.url, nil, rc, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if _, err = c.GetHealth(context.TODO()); (err != nil) != tt.wantErr {
+				t.Errorf("GetHealth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if proxiedHost != tt.wantProxied {
+				t.Errorf("proxied host got = %q, want %q", proxiedHost, tt.wantProxied)
+			}
+			if got := rc.GetClient().Timeout; got != tt.wantTimeout {
+				t.Errorf("timeout got = %v, want %v", got, tt.wantTimeout)
+			}
+		})
+	}
+}
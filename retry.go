@@ -0,0 +1,186 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how Client retries failed requests.
+// Network errors and 429, 502, 503 and 504 responses are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// A value below 2 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It doubles on every following retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed exponential backoff. A Retry-After header is never shortened:
+	// if it asks for a longer wait, the request is not retried and its error is returned.
+	// Zero means no cap.
+	MaxBackoff time.Duration
+	// Jitter is the fraction (0 to 1) of the backoff that is randomized.
+	Jitter float64
+	// RetryNonIdempotent enables retries for POST and PATCH requests.
+	// Use ContextWithRetry to opt in for a single call instead.
+	RetryNonIdempotent bool
+	// OnRetry, if set, is called before every retry.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	Method string
+	URL    string
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt int
+	// StatusCode is the status code of the failed attempt or 0 on network errors.
+	StatusCode int
+	Err        error
+	// Wait is the delay before the next attempt.
+	Wait time.Duration
+}
+
+// DefaultRetryPolicy returns a RetryPolicy suitable for riding out Grafana restarts.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+type retryContextKey struct{}
+
+// ContextWithRetry marks requests made with the returned context as safe to retry
+// regardless of their HTTP method, e.g. SetDashboard with Overwrite set.
+func ContextWithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, true)
+}
+
+// SetRetryPolicy sets the retry policy used by c. A nil policy disables retries.
+func (c *Client) SetRetryPolicy(p *RetryPolicy) {
+	c.retry = p
+}
+
+func (p *RetryPolicy) maxAttempts(ctx context.Context, method string) int {
+	if p == nil || p.MaxAttempts < 2 {
+		return 1
+	}
+	if p.RetryNonIdempotent || isIdempotent(method) {
+		return p.MaxAttempts
+	}
+	if retry, _ := ctx.Value(retryContextKey{}).(bool); retry {
+		return p.MaxAttempts
+	}
+	return 1
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether an attempt that ended with the given status code and error
+// is worth retrying.
+func shouldRetry(ctx context.Context, statusCode int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		// only transport failures are retried, not errors raised while building the request
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return isRetryableStatus(statusCode)
+}
+
+// backoff returns the wait before the retry following the given attempt.
+// A Retry-After header sent with a 429 or 503 response takes precedence and is not capped.
+func (p *RetryPolicy) backoff(attempt, statusCode int, header http.Header) time.Duration {
+	if statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable {
+		if d, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+	d := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 && d > 0 {
+		d -= time.Duration(rand.Float64() * min(p.Jitter, 1) * float64(d))
+	}
+	return d
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// allows reports whether the policy waits for d before retrying. Only a Retry-After header
+// can ask for more than MaxBackoff.
+func (p *RetryPolicy) allows(d time.Duration) bool {
+	return p.MaxBackoff <= 0 || d <= p.MaxBackoff
+}
+
+// sleep waits for d unless ctx is done first or its deadline would pass during the wait.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
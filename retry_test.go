@@ -0,0 +1,200 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		timeout      time.Duration
+		call         func(ctx context.Context, c *Client) error
+		failures     int32
+		retryAfter   string
+		maxBackoff   time.Duration
+		wantRequests int32
+		wantRetries  int
+		wantErr      bool
+	}{
+		{
+			name: "GET is retried until it succeeds",
+			ctx:  context.TODO(),
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetHealth(ctx)
+				return err
+			},
+			failures:     2,
+			wantRequests: 3,
+			wantRetries:  2,
+		},
+		{
+			name: "GET gives up after max attempts",
+			ctx:  context.TODO(),
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetHealth(ctx)
+				return err
+			},
+			failures:     10,
+			wantRequests: 3,
+			wantRetries:  2,
+			wantErr:      true,
+		},
+		{
+			name: "POST is not retried by default",
+			ctx:  context.TODO(),
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SetDashboard(ctx, &GrafanaDashboard{Dashboard: &runtime.RawExtension{Raw: []byte(`{}`)}, Overwrite: true})
+				return err
+			},
+			failures:     1,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name: "POST is retried when opted in",
+			ctx:  ContextWithRetry(context.TODO()),
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SetDashboard(ctx, &GrafanaDashboard{Dashboard: &runtime.RawExtension{Raw: []byte(`{}`)}, Overwrite: true})
+				return err
+			},
+			failures:     1,
+			wantRequests: 2,
+			wantRetries:  1,
+		},
+		{
+			name:    "Retry-After beyond the context deadline stops retrying",
+			ctx:     context.TODO(),
+			timeout: time.Second,
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetHealth(ctx)
+				return err
+			},
+			failures:     1,
+			retryAfter:   "120",
+			maxBackoff:   time.Hour,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name:    "Retry-After beyond MaxBackoff stops retrying",
+			ctx:     context.TODO(),
+			timeout: time.Second,
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetHealth(ctx)
+				return err
+			},
+			failures:     1,
+			retryAfter:   "3600",
+			wantRequests: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write([]byte(`{"database":"ok","status":"success"}`))
+			}))
+			defer srv.Close()
+
+			c, err := NewClient(srv.URL)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			var retries int
+			maxBackoff := 5 * time.Millisecond
+			if tt.maxBackoff > 0 {
+				maxBackoff = tt.maxBackoff
+			}
+			c.SetRetryPolicy(&RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     maxBackoff,
+				OnRetry: func(RetryEvent) {
+					retries++
+				},
+			})
+			ctx := tt.ctx
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			err = tt.call(ctx, c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var apiErr *APIError
+			if tt.wantErr && !errors.As(err, &apiErr) {
+				t.Errorf("error = %v, want *APIError", err)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests got = %v, want %v", requests, tt.wantRequests)
+			}
+			if retries != tt.wantRetries {
+				t.Errorf("retries got = %v, want %v", retries, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		name       string
+		attempt    int
+		statusCode int
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "first retry", attempt: 1, statusCode: http.StatusBadGateway, want: 100 * time.Millisecond},
+		{name: "third retry", attempt: 3, statusCode: http.StatusBadGateway, want: 400 * time.Millisecond},
+		{name: "capped", attempt: 10, statusCode: http.StatusBadGateway, want: time.Second},
+		{name: "Retry-After on 503", attempt: 3, statusCode: http.StatusServiceUnavailable, retryAfter: "0", want: 0},
+		{name: "Retry-After on 429", attempt: 1, statusCode: http.StatusTooManyRequests, retryAfter: "7", want: 7 * time.Second},
+		{name: "Retry-After ignored on 502", attempt: 1, statusCode: http.StatusBadGateway, retryAfter: "7", want: 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.retryAfter != "" {
+				header.Set("Retry-After", tt.retryAfter)
+			}
+			if got := p.backoff(tt.attempt, tt.statusCode, header); got != tt.want {
+				t.Errorf("backoff() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
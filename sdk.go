@@ -18,15 +18,11 @@ package grafana_sdk
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 
-	"github.com/go-resty/resty/v2"
-	"gomodules.xyz/pointer"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -47,13 +43,19 @@ type BasicAuth struct {
 type Client struct {
 	baseURL string
 	auth    *AuthConfig
-	client  *resty.Client
+	client  Doer
+	retry   *RetryPolicy
+	// userAgent and headers are sent with every request
+	userAgent string
+	headers   http.Header
+	// orgID, if set, is sent as X-Grafana-Org-Id header
+	orgID int
 }
 
 type GrafanaDashboard struct {
 	Dashboard *runtime.RawExtension `json:"dashboard,omitempty"`
 	FolderId  int                   `json:"folderId,omitempty"`
-	FolderUid string                `json:"FolderUid,omitempty"`
+	FolderUid string                `json:"folderUid,omitempty"`
 	Message   string                `json:"message,omitempty"`
 	Overwrite bool                  `json:"overwrite,omitempty"`
 }
@@ -68,6 +70,7 @@ type GrafanaResponse struct {
 	Status     *string `json:"status,omitempty"`
 	Version    *int    `json:"version,omitempty"`
 	Slug       *string `json:"slug,omitempty"`
+	OrgID      *int    `json:"orgId,omitempty"`
 	StatusCode int     `json:"statusCode,omitempty"`
 }
 
@@ -86,9 +89,12 @@ type HealthResponse struct {
 // http://docs.grafana.org/reference/http_api/#get-all-datasources
 type Datasource struct {
 	ID                uint    `json:"id"`
+	UID               string  `json:"uid,omitempty"`
 	OrgID             uint    `json:"orgId"`
 	Name              string  `json:"name"`
 	Type              string  `json:"type"`
+	TypeName          string  `json:"typeName,omitempty"`
+	TypeLogoURL       string  `json:"typeLogoUrl,omitempty"`
 	Access            string  `json:"access"` // direct or proxy
 	URL               string  `json:"url"`
 	Password          *string `json:"password,omitempty"`
@@ -97,29 +103,43 @@ type Datasource struct {
 	BasicAuth         *bool   `json:"basicAuth,omitempty"`
 	BasicAuthUser     *string `json:"basicAuthUser,omitempty"`
 	BasicAuthPassword *string `json:"basicAuthPassword,omitempty"`
+	WithCredentials   bool    `json:"withCredentials"`
 	IsDefault         bool    `json:"isDefault"`
 	JSONData          any     `json:"jsonData"`
 	SecureJSONData    any     `json:"secureJsonData"`
-}
-
-// NewClient initializes client for interacting with an instance of Grafana server;
-// apiKeyOrBasicAuth accepts either 'username:password' basic authentication credentials,
-// or a Grafana API key. If it is an empty string then no authentication is used.
-func NewClient(hostURL string, auth *AuthConfig, httpClient *resty.Client) (*Client, error) {
+	// SecureJSONFields is set by Grafana when datasources are read and tells which keys of
+	// SecureJSONData are set, as their values are never returned.
+	SecureJSONFields map[string]bool `json:"secureJsonFields,omitempty"`
+	// Version is incremented by Grafana on every update. If set on update, the update fails
+	// with a conflict if the datasource has been changed since it was read.
+	Version  int  `json:"version,omitempty"`
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// NewClient initializes client for interacting with an instance of Grafana server.
+// Without options requests are sent unauthenticated through a new http.Client;
+// see Option for configuring authentication, the transport, TLS, timeouts, proxy, headers and retries.
+func NewClient(hostURL string, opts ...Option) (*Client, error) {
 	baseURL, err := url.Parse(hostURL)
 	if err != nil {
 		return nil, err
 	}
-	client := &Client{
-		baseURL: baseURL.String(),
-		auth:    auth,
+	o := &clientOptions{}
+	for _, opt := range opts {
+		opt(o)
 	}
-	if httpClient == nil {
-		client.client = resty.New()
-	} else {
-		client.client = httpClient
+	doer, err := o.buildDoer()
+	if err != nil {
+		return nil, err
 	}
-	return client, nil
+	return &Client{
+		baseURL:   baseURL.String(),
+		auth:      o.auth,
+		client:    doer,
+		retry:     o.retry,
+		userAgent: o.userAgent,
+		headers:   o.headers,
+	}, nil
 }
 
 // SetDashboard will create or update grafana dashboard
@@ -130,18 +150,7 @@ func (c *Client) SetDashboard(ctx context.Context, db *GrafanaDashboard) (*Grafa
 	if err != nil {
 		return nil, err
 	}
-	gResp := &GrafanaResponse{}
-	err = json.Unmarshal(resp.Body(), gResp)
-	if err != nil {
-		return nil, err
-	}
-	gResp.StatusCode = resp.StatusCode()
-
-	if resp.StatusCode() != http.StatusOK {
-		return gResp, fmt.Errorf("failed to set dashboard, reason: %v", pointer.String(gResp.Message))
-	}
-
-	return gResp, nil
+	return grafanaResponse(resp)
 }
 
 // DeleteDashboardByUID will delete the grafana dashboard with the given uid
@@ -152,18 +161,7 @@ func (c *Client) DeleteDashboardByUID(ctx context.Context, uid string) (*Grafana
 	if err != nil {
 		return nil, err
 	}
-	gResp := &GrafanaResponse{}
-	err = json.Unmarshal(resp.Body(), gResp)
-	if err != nil {
-		return nil, err
-	}
-	gResp.StatusCode = resp.StatusCode()
-
-	if resp.StatusCode() != http.StatusOK {
-		return gResp, fmt.Errorf("failed to delete dashboard, reason: %v", pointer.String(gResp.Message))
-	}
-
-	return gResp, nil
+	return grafanaResponse(resp)
 }
 
 // GetCurrentOrg gets current organization.
@@ -175,12 +173,8 @@ func (c *Client) GetCurrentOrg(ctx context.Context) (*Org, error) {
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("failed to get current org, Status Code: %v", resp.StatusCode())
-	}
 	org := &Org{}
-	err = json.Unmarshal(resp.Body(), org)
-	if err != nil {
+	if err = decodeResponse(resp, org); err != nil {
 		return nil, err
 	}
 	return org, nil
@@ -196,43 +190,12 @@ func (c *Client) GetHealth(ctx context.Context) (*HealthResponse, error) {
 	}
 
 	health := &HealthResponse{}
-	err = json.Unmarshal(resp.Body(), health)
-	if err != nil {
+	if err = decodeResponse(resp, health); err != nil {
 		return nil, err
 	}
 	return health, nil
 }
 
-func (c *Client) do(ctx context.Context, method string, url string, body any) (*resty.Response, error) {
-	req := c.client.R().SetContext(ctx).SetBody(body)
-	if c.auth != nil {
-		if c.auth.BasicAuth != nil {
-			req = req.SetBasicAuth(c.auth.BasicAuth.Username, c.auth.BasicAuth.Password)
-		} else {
-			req = req.SetAuthToken(c.auth.BearerToken)
-		}
-	}
-
-	var resp *resty.Response
-	var err error
-	switch method {
-	case http.MethodGet:
-		resp, err = req.Get(url)
-	case http.MethodPost:
-		resp, err = req.Post(url)
-	case http.MethodDelete:
-		resp, err = req.Delete(url)
-	case http.MethodPut:
-		resp, err = req.Put(url)
-	default:
-		return nil, errors.New("unsupported http method")
-	}
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
-}
-
 func (c *Client) CreateDatasource(ctx context.Context, ds *Datasource) (*GrafanaResponse, error) {
 	u, _ := url.Parse(c.baseURL)
 	u.Path = path.Join(u.Path, "api/datasources")
@@ -240,18 +203,7 @@ func (c *Client) CreateDatasource(ctx context.Context, ds *Datasource) (*Grafana
 	if err != nil {
 		return nil, err
 	}
-	gResp := &GrafanaResponse{}
-	err = json.Unmarshal(resp.Body(), gResp)
-	if err != nil {
-		return nil, err
-	}
-	gResp.StatusCode = resp.StatusCode()
-
-	if resp.StatusCode() != http.StatusOK {
-		return gResp, fmt.Errorf("failed to create datasource, reason: %v", pointer.String(gResp.Message))
-	}
-
-	return gResp, nil
+	return grafanaResponse(resp)
 }
 
 func (c *Client) UpdateDatasource(ctx context.Context, ds Datasource) (*GrafanaResponse, error) {
@@ -261,18 +213,7 @@ func (c *Client) UpdateDatasource(ctx context.Context, ds Datasource) (*GrafanaR
 	if err != nil {
 		return nil, err
 	}
-	gResp := &GrafanaResponse{}
-	err = json.Unmarshal(resp.Body(), gResp)
-	if err != nil {
-		return nil, err
-	}
-	gResp.StatusCode = resp.StatusCode()
-
-	if resp.StatusCode() != http.StatusOK {
-		return gResp, fmt.Errorf("failed to update datasource, reason: %v", pointer.String(gResp.Message))
-	}
-
-	return gResp, nil
+	return grafanaResponse(resp)
 }
 
 func (c *Client) DeleteDatasource(ctx context.Context, id int) (*GrafanaResponse, error) {
@@ -282,16 +223,5 @@ func (c *Client) DeleteDatasource(ctx context.Context, id int) (*GrafanaResponse
 	if err != nil {
 		return nil, err
 	}
-	gResp := &GrafanaResponse{}
-	err = json.Unmarshal(resp.Body(), gResp)
-	if err != nil {
-		return nil, err
-	}
-	gResp.StatusCode = resp.StatusCode()
-
-	if resp.StatusCode() != http.StatusOK {
-		return gResp, fmt.Errorf("failed to delete datasource, reason: %v", pointer.String(gResp.Message))
-	}
-
-	return gResp, nil
+	return grafanaResponse(resp)
 }
@@ -19,11 +19,11 @@ package grafana_sdk
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"reflect"
 	"testing"
 
-	"github.com/go-resty/resty/v2"
 	"gomodules.xyz/pointer"
 	"gomodules.xyz/x/crypto/rand"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -52,7 +52,7 @@ func TestClient_CreateDatasource(t *testing.T) {
 	type fields struct {
 		baseURL string
 		auth    *AuthConfig
-		client  *resty.Client
+		client  Doer
 	}
 	type args struct {
 		ctx context.Context
@@ -70,7 +70,7 @@ func TestClient_CreateDatasource(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    validAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -121,7 +121,7 @@ func TestClient_UpdateDatasource(t *testing.T) {
 	type fields struct {
 		baseURL string
 		auth    *AuthConfig
-		client  *resty.Client
+		client  Doer
 	}
 	type args struct {
 		ctx context.Context
@@ -139,7 +139,7 @@ func TestClient_UpdateDatasource(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    validAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: nil,
@@ -187,7 +187,7 @@ func TestClient_DeleteDatasource(t *testing.T) {
 	type fields struct {
 		baseURL string
 		auth    *AuthConfig
-		client  *resty.Client
+		client  Doer
 	}
 	type args struct {
 		ctx context.Context
@@ -205,7 +205,7 @@ func TestClient_DeleteDatasource(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    validAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -218,7 +218,7 @@ func TestClient_DeleteDatasource(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    validAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -252,7 +252,7 @@ func TestClient_GetCurrentOrg(t *testing.T) {
 	type fields struct {
 		baseURL string
 		auth    *AuthConfig
-		client  *resty.Client
+		client  Doer
 	}
 	type args struct {
 		ctx context.Context
@@ -269,7 +269,7 @@ func TestClient_GetCurrentOrg(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    validAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -285,7 +285,7 @@ func TestClient_GetCurrentOrg(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    invalidAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -317,7 +317,7 @@ func TestClient_GetHealth(t *testing.T) {
 	type fields struct {
 		baseURL string
 		auth    *AuthConfig
-		client  *resty.Client
+		client  Doer
 	}
 	type args struct {
 		ctx context.Context
@@ -334,7 +334,7 @@ func TestClient_GetHealth(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    validAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -345,7 +345,7 @@ func TestClient_GetHealth(t *testing.T) {
 			name: "Test Get Health API without auth",
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -376,7 +376,7 @@ func TestClient_SetDashboard(t *testing.T) {
 	type fields struct {
 		baseURL string
 		auth    *AuthConfig
-		client  *resty.Client
+		client  Doer
 	}
 	type args struct {
 		ctx context.Context
@@ -393,7 +393,7 @@ func TestClient_SetDashboard(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    validAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -440,7 +440,7 @@ func TestClient_DeleteDashboardByUID(t *testing.T) {
 	type fields struct {
 		baseURL string
 		auth    *AuthConfig
-		client  *resty.Client
+		client  Doer
 	}
 	type args struct {
 		ctx context.Context
@@ -458,7 +458,7 @@ func TestClient_DeleteDashboardByUID(t *testing.T) {
 			fields: fields{
 				baseURL: GrafanaTestServerURL,
 				auth:    validAuth,
-				client:  resty.New(),
+				client:  &http.Client{},
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -485,7 +485,7 @@ func TestClient_DeleteDashboardByUID(t *testing.T) {
 }
 
 func createDS(name string) (*GrafanaResponse, error) {
-	client, err := NewClient(GrafanaTestServerURL, validAuth, nil)
+	client, err := NewClient(GrafanaTestServerURL, WithAuth(validAuth))
 	if err != nil {
 		return nil, err
 	}
@@ -504,7 +504,7 @@ func createDS(name string) (*GrafanaResponse, error) {
 }
 
 func deleteDS(id int) error {
-	client, err := NewClient(GrafanaTestServerURL, validAuth, nil)
+	client, err := NewClient(GrafanaTestServerURL, WithAuth(validAuth))
 	if err != nil {
 		return err
 	}
@@ -513,7 +513,7 @@ func deleteDS(id int) error {
 }
 
 func createDB(dbFilePath string) (*GrafanaResponse, error) {
-	client, err := NewClient(GrafanaTestServerURL, validAuth, nil)
+	client, err := NewClient(GrafanaTestServerURL, WithAuth(validAuth))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,158 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+)
+
+// SearchType restricts a search to dashboards or folders.
+type SearchType string
+
+const (
+	SearchTypeDashboard SearchType = "dash-db"
+	SearchTypeFolder    SearchType = "dash-folder"
+)
+
+const (
+	// defaultSearchPageSize is the page size used by SearchDashboardsIter if none is given.
+	defaultSearchPageSize = 1000
+	// maxSearchPageSize is the largest page size Grafana returns; larger limits are capped.
+	maxSearchPageSize = 5000
+)
+
+// SearchQuery holds the filters of a search. Zero values are not sent.
+type SearchQuery struct {
+	// Query is a search string matched against titles.
+	Query         string
+	Tags          []string
+	FolderUIDs    []string
+	DashboardUIDs []string
+	Type          SearchType
+	Starred       bool
+	// Sort is a sort option such as "alpha-asc" or "alpha-desc".
+	Sort string
+	// Limit is the maximum number of hits per page.
+	Limit int
+	// Page is the 1 based page number.
+	Page int
+}
+
+// SearchHit as described in the doc
+// https://grafana.com/docs/grafana/latest/developers/http_api/folder_dashboard_search/
+type SearchHit struct {
+	ID          int        `json:"id"`
+	UID         string     `json:"uid"`
+	Title       string     `json:"title"`
+	URI         string     `json:"uri,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	Slug        string     `json:"slug,omitempty"`
+	Type        SearchType `json:"type"`
+	Tags        []string   `json:"tags"`
+	IsStarred   bool       `json:"isStarred"`
+	FolderID    int        `json:"folderId,omitempty"`
+	FolderUID   string     `json:"folderUid,omitempty"`
+	FolderTitle string     `json:"folderTitle,omitempty"`
+	FolderURL   string     `json:"folderUrl,omitempty"`
+	SortMeta    int64      `json:"sortMeta,omitempty"`
+}
+
+func (q SearchQuery) values() url.Values {
+	v := url.Values{}
+	if q.Query != "" {
+		v.Set("query", q.Query)
+	}
+	for _, tag := range q.Tags {
+		v.Add("tag", tag)
+	}
+	for _, uid := range q.FolderUIDs {
+		v.Add("folderUIDs", uid)
+	}
+	for _, uid := range q.DashboardUIDs {
+		v.Add("dashboardUIDs", uid)
+	}
+	if q.Type != "" {
+		v.Set("type", string(q.Type))
+	}
+	if q.Starred {
+		v.Set("starred", "true")
+	}
+	if q.Sort != "" {
+		v.Set("sort", q.Sort)
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Page > 0 {
+		v.Set("page", strconv.Itoa(q.Page))
+	}
+	return v
+}
+
+// SearchDashboards returns a single page of dashboards and folders matching q.
+// It reflects GET /api/search API call.
+func (c *Client) SearchDashboards(ctx context.Context, q SearchQuery) ([]SearchHit, error) {
+	u, _ := url.Parse(c.baseURL)
+	u.Path = path.Join(u.Path, "api/search")
+	u.RawQuery = q.values().Encode()
+	resp, err := c.do(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var hits []SearchHit
+	if err = decodeResponse(resp, &hits); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// SearchDashboardsIter iterates over all hits matching q, fetching one page at a time
+// starting at q.Page. q.Limit sets the page size, at most 5000. Iteration stops after the first error,
+// which is yielded with a zero SearchHit.
+func (c *Client) SearchDashboardsIter(ctx context.Context, q SearchQuery) iter.Seq2[SearchHit, error] {
+	return func(yield func(SearchHit, error) bool) {
+		if q.Limit <= 0 {
+			q.Limit = defaultSearchPageSize
+		}
+		// Grafana caps larger pages, which would otherwise look like the last one
+		q.Limit = min(q.Limit, maxSearchPageSize)
+		if q.Page <= 0 {
+			q.Page = 1
+		}
+		for {
+			hits, err := c.SearchDashboards(ctx, q)
+			if err != nil {
+				yield(SearchHit{}, err)
+				return
+			}
+			for _, hit := range hits {
+				if !yield(hit, nil) {
+					return
+				}
+			}
+			if len(hits) < q.Limit {
+				return
+			}
+			q.Page++
+		}
+	}
+}
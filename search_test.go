@@ -0,0 +1,135 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSearchQuery_values(t *testing.T) {
+	tests := []struct {
+		name string
+		q    SearchQuery
+		want url.Values
+	}{
+		{
+			name: "Empty query",
+			want: url.Values{},
+		},
+		{
+			name: "All filters",
+			q: SearchQuery{
+				Query:         "postgres",
+				Tags:          []string{"db", "stats"},
+				FolderUIDs:    []string{"databases"},
+				DashboardUIDs: []string{"a", "b"},
+				Type:          SearchTypeDashboard,
+				Starred:       true,
+				Sort:          "alpha-asc",
+				Limit:         50,
+				Page:          2,
+			},
+			want: url.Values{
+				"query":         {"postgres"},
+				"tag":           {"db", "stats"},
+				"folderUIDs":    {"databases"},
+				"dashboardUIDs": {"a", "b"},
+				"type":          {"dash-db"},
+				"starred":       {"true"},
+				"sort":          {"alpha-asc"},
+				"limit":         {"50"},
+				"page":          {"2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.values(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_SearchDashboardsIter(t *testing.T) {
+	var total int
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		limit = min(limit, 5000)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, r.URL.Query().Get("page"))
+		hits := []SearchHit{}
+		for i := (page - 1) * limit; i < total && i < page*limit; i++ {
+			hits = append(hits, SearchHit{ID: i, UID: fmt.Sprintf("uid-%d", i), Type: SearchTypeDashboard})
+		}
+		_ = json.NewEncoder(w).Encode(hits)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithAuth(validAuth))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		total     int
+		limit     int
+		stopAfter int
+		wantHits  int
+		wantPages []string
+	}{
+		{name: "Partial last page", total: 7, limit: 3, wantHits: 7, wantPages: []string{"1", "2", "3"}},
+		{name: "Full last page", total: 7, limit: 7, wantHits: 7, wantPages: []string{"1", "2"}},
+		{name: "Early break", total: 7, limit: 3, stopAfter: 4, wantHits: 4, wantPages: []string{"1", "2"}},
+		{name: "Limit above the Grafana maximum", total: 5007, limit: 6000, wantHits: 5007, wantPages: []string{"1", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total = tt.total
+			pages = nil
+			var got int
+			for hit, err := range c.SearchDashboardsIter(context.TODO(), SearchQuery{Type: SearchTypeDashboard, Limit: tt.limit}) {
+				if err != nil {
+					t.Fatalf("SearchDashboardsIter() error = %v", err)
+				}
+				if hit.ID != got {
+					t.Errorf("hit got = %v, want %v", hit.ID, got)
+				}
+				got++
+				if got == tt.stopAfter {
+					break
+				}
+			}
+			if got != tt.wantHits {
+				t.Errorf("hits got = %v, want %v", got, tt.wantHits)
+			}
+			if !reflect.DeepEqual(pages, tt.wantPages) {
+				t.Errorf("pages got = %v, want %v", pages, tt.wantPages)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Doer sends an HTTP request and returns its response. *http.Client implements it;
+// other HTTP stacks can be plugged in through WithDoer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// response is a fully read HTTP response.
+type response struct {
+	method     string
+	url        string
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+func (r *response) isSuccess() bool {
+	return r.statusCode >= 200 && r.statusCode < 300
+}
+
+func (c *Client) do(ctx context.Context, method string, url string, body any) (*response, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to encode request body of %s %s, reason: %w", method, url, err)
+		}
+	}
+	return c.doRaw(ctx, method, url, data, "application/json")
+}
+
+// doRaw is like do, but sends data as is with the given content type.
+func (c *Client) doRaw(ctx context.Context, method string, url string, data []byte, contentType string) (*response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	attempts := c.retry.maxAttempts(ctx, method)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doOnce(ctx, method, url, data, contentType)
+		if attempt >= attempts {
+			return resp, err
+		}
+		var statusCode int
+		var header http.Header
+		if resp != nil {
+			statusCode = resp.statusCode
+			header = resp.header
+		}
+		if !shouldRetry(ctx, statusCode, err) {
+			return resp, err
+		}
+		wait := c.retry.backoff(attempt, statusCode, header)
+		if !c.retry.allows(wait) || !sleep(ctx, wait) {
+			return resp, err
+		}
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(RetryEvent{
+				Method:     method,
+				URL:        url,
+				Attempt:    attempt,
+				StatusCode: statusCode,
+				Err:        err,
+				Wait:       wait,
+			})
+		}
+	}
+}
+
+func (c *Client) doOnce(ctx context.Context, method string, url string, data []byte, contentType string) (*response, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.headers {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	if data != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.orgID > 0 {
+		req.Header.Set(orgIDHeader, strconv.Itoa(c.orgID))
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.auth != nil {
+		if c.auth.BasicAuth != nil {
+			req.SetBasicAuth(c.auth.BasicAuth.Username, c.auth.BasicAuth.Password)
+		} else if c.auth.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.auth.BearerToken)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s %s, reason: %w", method, url, err)
+	}
+	return &response{
+		method:     method,
+		url:        url,
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       respBody,
+	}, nil
+}
+
+// checkResponse returns an *APIError if resp does not carry a 2xx status code.
+func checkResponse(resp *response) error {
+	if resp.isSuccess() {
+		return nil
+	}
+	return newAPIError(resp.method, resp.url, resp.statusCode, resp.body)
+}
+
+// decodeResponse checks the status code of resp and decodes its JSON body into out.
+func decodeResponse(resp *response, out any) error {
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resp.body, out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s, reason: %w", resp.method, resp.url, err)
+	}
+	return nil
+}
+
+// grafanaResponse decodes resp into a GrafanaResponse. If Grafana reported an error,
+// the decoded response is returned along with the *APIError.
+func grafanaResponse(resp *response) (*GrafanaResponse, error) {
+	gResp := &GrafanaResponse{}
+	err := decodeResponse(resp, gResp)
+	if err != nil {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			return nil, err
+		}
+		_ = json.Unmarshal(resp.body, gResp)
+	}
+	gResp.StatusCode = resp.statusCode
+	return gResp, err
+}
@@ -0,0 +1,112 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grafana_sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClient_do(t *testing.T) {
+	var gotMethod string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var recorded []string
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		recorded = append(recorded, req.Method+" "+req.URL.Path)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	c, err := NewClient(srv.URL, WithRoundTripper(rt))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+	}{
+		{name: "PATCH", method: http.MethodPatch},
+		{name: "HEAD", method: http.MethodHead},
+		{name: "GET", method: http.MethodGet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.do(context.TODO(), tt.method, srv.URL+"/api/health", nil)
+			if err != nil {
+				t.Errorf("do() error = %v", err)
+				return
+			}
+			if resp.statusCode != http.StatusOK {
+				t.Errorf("do() statusCode = %v, want %v", resp.statusCode, http.StatusOK)
+			}
+			if gotMethod != tt.method {
+				t.Errorf("server got method = %v, want %v", gotMethod, tt.method)
+			}
+		})
+	}
+	if len(recorded) != len(tests) {
+		t.Errorf("round tripper recorded %v requests, want %v", len(recorded), len(tests))
+	}
+}
+
+func TestNewClient_TransportOptions(t *testing.T) {
+	rt := roundTripperFunc(http.DefaultTransport.RoundTrip)
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			name: "TLS options on default transport",
+			opts: []Option{WithInsecureSkipVerify()},
+		},
+		{
+			name: "TLS options on custom http.Client",
+			opts: []Option{WithHTTPClient(&http.Client{Transport: &http.Transport{}}), WithProxy("http://proxy:3128")},
+		},
+		{
+			name:    "TLS options on custom RoundTripper",
+			opts:    []Option{WithRoundTripper(rt), WithInsecureSkipVerify()},
+			wantErr: true,
+		},
+		{
+			name:    "Timeout on custom Doer",
+			opts:    []Option{WithDoer(http.DefaultClient), WithTimeout(1)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient("http://localhost:3000", tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}